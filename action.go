@@ -36,6 +36,36 @@ const (
 	// Deprecated
 	RtSettingStepMode     = "stepRecordingMode"
 	RtSettingSnapshotMode = "snapshotRecordingMode"
+
+	StateRecordingAsync        = "stateRecordingAsync"
+	StateRecordingBufferSize   = "stateRecordingBufferSize"
+	StateRecordingBackpressure = "stateRecordingBackpressure"
+	StateRecordingSampling     = "stateRecordingSampling"
+	StateRecordingSampleEvery  = "stateRecordingSampleEvery"
+	StateRecorders             = "stateRecorders"
+	StateRecordingRetries      = "stateRecordingRetries"
+	StateRecordingRetryBackoff = "stateRecordingRetryBackoff"
+	FailOnRecorderError        = "failOnRecorderError"
+
+	RtSettingDefaultFlowTimeout = "defaultFlowTimeout"
+	RtSettingMaxInputBytes      = "maxInputBytes"
+	RtSettingMaxOutputBytes     = "maxOutputBytes"
+	RtSettingFlowAliases        = "flowAliases"
+	RtSettingSensitiveFields    = "sensitiveFields"
+	RtSettingNumberMode         = "numberMode"
+	RtSettingTrackMemory        = "trackInstanceMemory"
+	RtSettingMaxInstanceMemory  = "maxInstanceMemory"
+	RtSettingDuplicateIDPolicy  = "duplicateInstanceIdPolicy"
+	RtSettingInstanceRetention  = "instanceRetention"
+	RtSettingLogSampleEvery     = "logSampleEvery"
+	RtSettingDefCacheSize       = "definitionCacheSize"
+	RtSettingDefCacheTTL        = "definitionCacheTTL"
+	RtSettingMaxConcurrent      = "maxConcurrentInstances"
+	RtSettingSuspendedStateTTL  = "suspendedStateTTL"
+	RtSettingStaleStatePolicy   = "staleStatePolicy"
+	RtSettingTraceFile          = "traceFile"
+	RtSettingTraceFileMaxBytes  = "traceFileMaxBytes"
+	RtSettingMaxExecTraceSteps  = "maxExecutionTraceSteps"
 )
 
 var idGenerator *support.Generator
@@ -45,6 +75,10 @@ var logger log.Logger
 var flowManager *flowsupport.FlowManager
 var stateRecorder state.Recorder
 var stateRecordingMode = state.RecordingModeOff
+var defaultFlowTimeout time.Duration
+var maxInputBytes int
+var maxOutputBytes int
+var failOnRecorderError bool
 
 type ActionFactory struct {
 	resManager *resource.Manager
@@ -61,9 +95,25 @@ func (f *ActionFactory) Initialize(ctx action.InitContext) error {
 
 	sm := ctx.ServiceManager()
 
-	srService := sm.FindService(func(s service.Service) bool {
-		_, ok := s.(state.Recorder)
-		return ok
+	// by default the first recorder service is used, the stateRecorders runtime setting selects
+	// the recorder services (by name) to record to
+	var selected []string
+	if val, ok := ctx.RuntimeSettings()[StateRecorders]; ok {
+		sNames, _ := coerce.ToString(val)
+		for _, name := range strings.Split(sNames, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				selected = append(selected, name)
+			}
+		}
+	}
+
+	var recorders []state.Recorder
+	sm.FindService(func(s service.Service) bool {
+		recorder, ok := s.(state.Recorder)
+		if ok && ((len(selected) == 0 && len(recorders) == 0) || containsString(selected, s.Name())) {
+			recorders = append(recorders, recorder)
+		}
+		return false
 	})
 
 	if len(ctx.RuntimeSettings()) > 0 {
@@ -97,11 +147,180 @@ func (f *ActionFactory) Initialize(ctx action.InitContext) error {
 
 	}
 
-	if srService != nil {
-		stateRecorder = srService.(state.Recorder)
+	if len(recorders) > 0 {
+		// each recorder is retried on its own, so a failing recorder doesn't record again to
+		// the recorders that succeeded
+		if val, ok := ctx.RuntimeSettings()[StateRecordingRetries]; ok {
+			retries, err := coerce.ToInt(val)
+			if err != nil {
+				return fmt.Errorf("invalid runtime setting '%s': %s", StateRecordingRetries, err.Error())
+			}
+			backoff, err := toDuration(ctx.RuntimeSettings()[StateRecordingRetryBackoff])
+			if err != nil {
+				return fmt.Errorf("invalid runtime setting '%s': %s", StateRecordingRetryBackoff, err.Error())
+			}
+			for i, recorder := range recorders {
+				recorders[i] = state.NewRetryRecorder(recorder, retries, backoff)
+			}
+		}
+
+		if len(recorders) == 1 {
+			stateRecorder = state.NewInstrumentedRecorder(recorders[0])
+		} else {
+			stateRecorder = state.NewInstrumentedRecorder(state.NewMultiRecorder(recorders...))
+		}
+
+		if attributeEncryptor != nil {
+			stateRecorder = state.NewEncryptingRecorder(stateRecorder, attributeEncryptor, encryptedAttrs)
+		}
+
+		failOnRecorderError, _ = coerce.ToBool(ctx.RuntimeSettings()[FailOnRecorderError])
+		instance.SetFailOnRecorderError(failOnRecorderError)
+
+		async, _ := coerce.ToBool(ctx.RuntimeSettings()[StateRecordingAsync])
+		if async && failOnRecorderError {
+			// the errors of asynchronous recording are only logged, they can't fail the instance
+			return fmt.Errorf("runtime setting '%s' can't be used with '%s'", FailOnRecorderError, StateRecordingAsync)
+		}
+		if async {
+			bufferSize, _ := coerce.ToInt(ctx.RuntimeSettings()[StateRecordingBufferSize])
+			sPolicy, _ := coerce.ToString(ctx.RuntimeSettings()[StateRecordingBackpressure])
+			policy, err := state.ToBackpressurePolicy(sPolicy)
+			if err != nil {
+				return err
+			}
+			stateRecorder = state.NewAsyncRecorder(stateRecorder, bufferSize, policy, logger)
+		}
+
 		if state.RecordSteps(stateRecordingMode) {
 			instance.EnableChangeTracking(true, stateRecordingMode)
 		}
+
+		sStrategy, _ := coerce.ToString(ctx.RuntimeSettings()[StateRecordingSampling])
+		sampler, err := state.NewSampler(sStrategy, ctx.RuntimeSettings()[StateRecordingSampleEvery])
+		if err != nil {
+			return fmt.Errorf("invalid runtime setting '%s': %s", StateRecordingSampling, err.Error())
+		}
+		instance.SetRecordingSampler(sampler)
+	}
+
+	var err error
+	defaultFlowTimeout, err = toDuration(ctx.RuntimeSettings()[RtSettingDefaultFlowTimeout])
+	if err != nil {
+		return fmt.Errorf("invalid runtime setting '%s': %s", RtSettingDefaultFlowTimeout, err.Error())
+	}
+
+	if val, ok := ctx.RuntimeSettings()[RtSettingFlowAliases]; ok {
+		aliases, err := coerce.ToParams(val)
+		if err != nil {
+			return fmt.Errorf("invalid runtime setting '%s': %s", RtSettingFlowAliases, err.Error())
+		}
+		flowsupport.SetFlowAliases(aliases)
+	}
+
+	if val, ok := ctx.RuntimeSettings()[RtSettingSensitiveFields]; ok {
+		fields, err := coerce.ToArray(val)
+		if err != nil {
+			return fmt.Errorf("invalid runtime setting '%s': %s", RtSettingSensitiveFields, err.Error())
+		}
+		names := make([]string, 0, len(fields))
+		for _, field := range fields {
+			name, _ := coerce.ToString(field)
+			names = append(names, strings.Split(name, ",")...)
+		}
+		SetSensitiveFields(names)
+	}
+
+	if val, ok := ctx.RuntimeSettings()[RtSettingNumberMode]; ok {
+		sMode, _ := coerce.ToString(val)
+		numberMode, err = ToNumberMode(sMode)
+		if err != nil {
+			return fmt.Errorf("invalid runtime setting '%s': %s", RtSettingNumberMode, err.Error())
+		}
+	}
+
+	trackInstanceMemory, _ = coerce.ToBool(ctx.RuntimeSettings()[RtSettingTrackMemory])
+	if val, ok := ctx.RuntimeSettings()[RtSettingMaxInstanceMemory]; ok {
+		maxInstanceMemory, err = coerce.ToInt(val)
+		if err != nil {
+			return fmt.Errorf("invalid runtime setting '%s': %s", RtSettingMaxInstanceMemory, err.Error())
+		}
+	}
+
+	instanceRetention, err = toDuration(ctx.RuntimeSettings()[RtSettingInstanceRetention])
+	if err != nil {
+		return fmt.Errorf("invalid runtime setting '%s': %s", RtSettingInstanceRetention, err.Error())
+	}
+
+	if val, ok := ctx.RuntimeSettings()[RtSettingDuplicateIDPolicy]; ok {
+		sPolicy, _ := coerce.ToString(val)
+		duplicateIDPolicy, err = ToDuplicateIDPolicy(sPolicy)
+		if err != nil {
+			return fmt.Errorf("invalid runtime setting '%s': %s", RtSettingDuplicateIDPolicy, err.Error())
+		}
+	}
+
+	if val, ok := ctx.RuntimeSettings()[RtSettingTraceFile]; ok {
+		path, _ := coerce.ToString(val)
+		maxBytes, err := coerce.ToInt64(ctx.RuntimeSettings()[RtSettingTraceFileMaxBytes])
+		if err != nil {
+			return fmt.Errorf("invalid runtime setting '%s': %s", RtSettingTraceFileMaxBytes, err.Error())
+		}
+		if path != "" {
+			traceFile, err = newTraceFileWriter(path, maxBytes)
+			if err != nil {
+				return fmt.Errorf("unable to open trace file '%s': %s", path, err.Error())
+			}
+		}
+	}
+
+	suspendedStateTTL, err = toDuration(ctx.RuntimeSettings()[RtSettingSuspendedStateTTL])
+	if err != nil {
+		return fmt.Errorf("invalid runtime setting '%s': %s", RtSettingSuspendedStateTTL, err.Error())
+	}
+
+	if val, ok := ctx.RuntimeSettings()[RtSettingStaleStatePolicy]; ok {
+		sPolicy, _ := coerce.ToString(val)
+		staleStatePolicy, err = ToStaleStatePolicy(sPolicy)
+		if err != nil {
+			return fmt.Errorf("invalid runtime setting '%s': %s", RtSettingStaleStatePolicy, err.Error())
+		}
+	}
+
+	if val, ok := ctx.RuntimeSettings()[RtSettingMaxConcurrent]; ok {
+		maxConcurrentInstances, err = coerce.ToInt(val)
+		if err != nil {
+			return fmt.Errorf("invalid runtime setting '%s': %s", RtSettingMaxConcurrent, err.Error())
+		}
+	}
+
+	if val, ok := ctx.RuntimeSettings()[RtSettingLogSampleEvery]; ok {
+		logSampleEvery, err = coerce.ToInt(val)
+		if err != nil {
+			return fmt.Errorf("invalid runtime setting '%s': %s", RtSettingLogSampleEvery, err.Error())
+		}
+	}
+
+	if val, ok := ctx.RuntimeSettings()[RtSettingMaxExecTraceSteps]; ok {
+		maxSteps, err := coerce.ToInt(val)
+		if err != nil {
+			return fmt.Errorf("invalid runtime setting '%s': %s", RtSettingMaxExecTraceSteps, err.Error())
+		}
+		instance.SetMaxExecTraceSteps(maxSteps)
+	}
+
+	if val, ok := ctx.RuntimeSettings()[RtSettingMaxInputBytes]; ok {
+		maxInputBytes, err = coerce.ToInt(val)
+		if err != nil {
+			return fmt.Errorf("invalid runtime setting '%s': %s", RtSettingMaxInputBytes, err.Error())
+		}
+	}
+
+	if val, ok := ctx.RuntimeSettings()[RtSettingMaxOutputBytes]; ok {
+		maxOutputBytes, err = coerce.ToInt(val)
+		if err != nil {
+			return fmt.Errorf("invalid runtime setting '%s': %s", RtSettingMaxOutputBytes, err.Error())
+		}
 	}
 
 	exprFactory := expression.NewFactory(definition.GetDataResolver())
@@ -117,6 +336,19 @@ func (f *ActionFactory) Initialize(ctx action.InitContext) error {
 	//todo fix the following
 	model.RegisterDefault(simple.New())
 	flowManager = flowsupport.NewFlowManager(nil)
+
+	// by default definitions are never evicted, setting a cache size or TTL bounds the cache
+	defCacheSize, err := coerce.ToInt(ctx.RuntimeSettings()[RtSettingDefCacheSize])
+	if err != nil {
+		return fmt.Errorf("invalid runtime setting '%s': %s", RtSettingDefCacheSize, err.Error())
+	}
+	defCacheTTL, err := toDuration(ctx.RuntimeSettings()[RtSettingDefCacheTTL])
+	if err != nil {
+		return fmt.Errorf("invalid runtime setting '%s': %s", RtSettingDefCacheTTL, err.Error())
+	}
+	if defCacheSize > 0 || defCacheTTL > 0 {
+		flowManager.SetDefinitionCache(flowsupport.NewLRUDefinitionCache(defCacheSize, defCacheTTL))
+	}
 	flowsupport.InitDefaultDefLookup(flowManager, ctx.ResourceManager())
 
 	return nil
@@ -144,6 +376,52 @@ func (f *ActionFactory) New(config *action.Config) (action.Action, error) {
 
 	flowAction.ioMetadata = def.Metadata()
 
+	flowAction.inputMapper, err = newInputMapper(settings.InputMappings)
+	if err != nil {
+		return nil, fmt.Errorf("invalid input mappings for flow [%s]: %s", flowAction.flowURI, err.Error())
+	}
+
+	if settings.StateRecordingMode != "" {
+		flowAction.recordingMode, err = state.ToRecordingMode(settings.StateRecordingMode)
+		if err != nil {
+			return nil, fmt.Errorf("invalid state recording mode for flow [%s]: %s", flowAction.flowURI, err.Error())
+		}
+	}
+
+	flowAction.outputMapper, err = newOutputMapper(settings.OutputMapper)
+	if err != nil {
+		return nil, fmt.Errorf("invalid output mapper for flow [%s]: %s", flowAction.flowURI, err.Error())
+	}
+
+	if settings.RateLimit > 0 {
+		wait, err := toDuration(settings.RateLimitWait)
+		if err != nil {
+			return nil, fmt.Errorf("invalid rate limit wait for flow [%s]: %s", flowAction.flowURI, err.Error())
+		}
+		setRateLimit(flowAction.flowURI, settings.RateLimit, settings.RateBurst, wait)
+	}
+
+	if settings.Cacheable {
+		flowAction.cacheTTL, err = toDuration(settings.CacheTTL)
+		if err != nil || flowAction.cacheTTL <= 0 {
+			return nil, fmt.Errorf("invalid cache TTL for cacheable flow [%s]: '%s'", flowAction.flowURI, settings.CacheTTL)
+		}
+	}
+
+	flowAction.instanceIDField = settings.InstanceIDFromInput
+	flowAction.validateOutputs = settings.ValidateOutputs
+	flowAction.allowEmptyInputs = settings.AllowEmptyInputs
+
+	flowAction.maxStepCount = settings.MaxStepCount
+	if flowAction.maxStepCount <= 0 {
+		flowAction.maxStepCount = flowStepLimit(def)
+	}
+
+	flowAction.defaultTimeout, err = toDuration(settings.DefaultTimeout)
+	if err != nil {
+		return nil, fmt.Errorf("invalid default timeout for flow [%s]: %s", flowAction.flowURI, err.Error())
+	}
+
 	if res {
 		flowAction.resFlow = def
 	}
@@ -152,10 +430,26 @@ func (f *ActionFactory) New(config *action.Config) (action.Action, error) {
 }
 
 type FlowAction struct {
-	flowURI    string
-	resFlow    *definition.Definition
-	ioMetadata *metadata.IOMetadata
-	info       *action.Info
+	flowURI      string
+	resFlow      *definition.Definition
+	ioMetadata   *metadata.IOMetadata
+	info         *action.Info
+	inputMapper  mapper.Mapper
+	outputMapper mapper.Mapper
+	// recordingMode overrides the engine's state recording mode when set
+	recordingMode state.RecordingMode
+	// cacheTTL is how long the results are cached for, results are only cached when set
+	cacheTTL time.Duration
+	// instanceIDField is the input the instance ID is derived from, when set
+	instanceIDField string
+	// validateOutputs checks the return data against the flow's declared outputs
+	validateOutputs bool
+	// defaultTimeout is the maximum duration of the flow's instances, when set
+	defaultTimeout time.Duration
+	// allowEmptyInputs starts the flow with its declared defaults when invoked without inputs
+	allowEmptyInputs bool
+	// maxStepCount is the maximum number of steps of the flow's instances
+	maxStepCount int
 }
 
 func (fa *FlowAction) Info() *action.Info {
@@ -181,6 +475,15 @@ func (fa *FlowAction) Run(ctx context.Context, inputs map[string]interface{}, ha
 	var preserveInstanceId string
 	var initStepId int
 	var rerun bool
+	var resumeToken string
+	var subflowOptions *instance.SubflowOptions
+	var labels map[string]string
+	var attrOverrides map[string]interface{}
+	var retryCount int
+	var correlationID string
+	var cacheKey string
+	var resumeDef *definition.Definition
+	var priority int
 	runOptions, exists := inputs["_run_options"]
 
 	var execOptions *instance.ExecOptions
@@ -196,62 +499,200 @@ func (fa *FlowAction) Run(ctx context.Context, inputs map[string]interface{}, ha
 			execOptions = ro.ExecOptions
 			initStepId = ro.InitStepId
 			rerun = ro.Rerun
+			resumeToken = ro.ResumeToken
+			subflowOptions = ro.SubflowOptions
+			labels = ro.Labels
+			attrOverrides = ro.AttrOverrides
+			retryCount = ro.RetryCount
+			correlationID = ro.CorrelationID
+			resumeDef = ro.Definition
+			priority = ro.Priority
 		}
 	}
 
 	delete(inputs, "_run_options")
 
+	inputs, err = decodeInputs(ctx, inputs)
+	if err != nil {
+		return err
+	}
+
+	if maxInputBytes > 0 {
+		if size := flowsupport.EstimateSize(inputs); size > maxInputBytes {
+			return fmt.Errorf("flow inputs of approximately %d bytes exceed the maximum of %d bytes", size, maxInputBytes)
+		}
+	}
+
 	if flowURI == "" {
 		flowURI = fa.flowURI
 	}
+	flowURI = flowsupport.ResolveFlowURI(flowURI)
+
+	// with a start timeout the caller waits for the instance to be allowed to run
+	var startRelease func()
+	started := false
+	if execOptions != nil && execOptions.StartTimeout > 0 {
+		startRelease, err = instanceLimiter.acquireWithin(ctx, maxConcurrentInstances, priority, execOptions.StartTimeout)
+		if err != nil {
+			return err
+		}
+		defer func() {
+			if !started {
+				startRelease()
+			}
+		}()
+	}
+
+	if op == instance.OpResume {
+		if initialState == nil && resumeToken != "" {
+			if err := checkSuspendedStateExpired(resumeToken, time.Now()); err != nil {
+				return err
+			}
+			initialState, err = takeSuspendedInstance(resumeToken)
+			if err == ErrNotSuspended {
+				return fmt.Errorf("unable to resume instance, no instance suspended with token [%s]", resumeToken)
+			}
+			if err != nil {
+				return fmt.Errorf("unable to resume instance suspended with token [%s]: %s", resumeToken, err.Error())
+			}
+		}
+
+		// a cancel may have been requested while the instance was suspended
+		if initialState != nil {
+			if err := checkCancelled(initialState.ID()); err != nil {
+				return err
+			}
+		}
+
+		if initialState != nil && isStateStale(initialState, suspendedStateTTL, time.Now()) {
+			if staleStatePolicy != StaleStateRestart {
+				return &StateExpiredError{ID: initialState.ID(), SuspendedAt: initialState.SuspendedAt()}
+			}
+			// start the flow again, from its original inputs
+			logger.Infof("State of suspended Flow Instance [%s] expired, restarting the flow", initialState.LogID())
+			op = instance.OpStart
+			flowURI = initialState.FlowURI()
+			inputs = initialState.Inputs()
+			initialState = nil
+		} else if initialState != nil && resumeToken != "" {
+			initialState.ClearSuspend()
+		}
+	}
+
+	recordingMode := stateRecordingMode
+	if fa.recordingMode != "" {
+		recordingMode = fa.recordingMode
+	}
+
+	// the recording predicate selects the instances whose state is recorded
+	recorder := stateRecorder
+	if recorder != nil && recordingPredicate != nil && !recordingPredicate(flowURI, inputs) {
+		recorder = nil
+	}
 
 	if flowURI == "" {
 		return fmt.Errorf("cannot run flow, flowURI not specified")
 	}
 
+	// keep the original inputs, the flow is retried with the same inputs
+	var retryInputs map[string]interface{}
+	if op == instance.OpStart && execOptions != nil && execOptions.FlowRetry != nil {
+		retryInputs = make(map[string]interface{}, len(inputs))
+		for name, value := range inputs {
+			retryInputs[name] = value
+		}
+	}
+
 	logger.Debugf("Running FlowAction for URI: '%s'", flowURI)
 
 	//todo: catch panic
 	//todo: consider switch to URI to dictate flow operation (ex. flow://blah/resume)
 
 	var inst *instance.IndependentInstance
+	// the policy applied when the instance is registered, only a start with a preserved or input
+	// instance ID can collide with a running instance
+	idPolicy := DuplicateIDIgnore
 	switch op {
 	case instance.OpStart:
 
+		if err := admit(flowURI, inputs); err != nil {
+			return err
+		}
+
+		if err := acquireRateLimit(flowURI); err != nil {
+			return err
+		}
+
 		flowDef := fa.resFlow
 
 		if flowDef == nil {
 			var err error
 			flowDef, err = flowManager.GetFlow(flowURI)
 			if err != nil {
-				return err
+				return flowNotFound(flowURI, err, handler)
 			}
 
 			if flowDef == nil {
-				return errors.New("flow not found for URI: " + flowURI)
+				return flowNotFound(flowURI, errors.New("flow not found for URI: "+flowURI), handler)
+			}
+		}
+
+		if fa.inputMapper != nil {
+			inputs, err = applyInputMapper(fa.inputMapper, flowURI, inputs)
+			if err != nil {
+				return err
+			}
+		}
+
+		if len(inputs) == 0 && !fa.allowEmptyInputs {
+			if err := checkEmptyInputs(flowURI, flowDef.Metadata()); err != nil {
+				return err
+			}
+		}
+
+		if fa.cacheTTL > 0 {
+			cacheKey, err = resultCacheKey(flowURI, flowDef, inputs)
+			if err != nil {
+				logger.Warnf("Unable to compute cache key for flow [%s], its results are not cached: %v", flowURI, err)
+			} else if cachedResults(cacheKey, handler) {
+				logger.Debugf("Returning cached results for flow [%s]", flowURI)
+				return nil
 			}
 		}
 
 		var instanceID string
 		if len(preserveInstanceId) > 0 {
 			instanceID = preserveInstanceId
+			idPolicy = duplicateIDPolicy
+			ReleaseInstanceID(instanceID)
+		} else if fa.instanceIDField != "" {
+			instanceID, err = instanceIDFromInput(flowURI, fa.instanceIDField, inputs)
+			if err != nil {
+				return err
+			}
+			idPolicy = duplicateIDPolicy
 		} else {
 			instanceID = idGenerator.NextAsString()
 		}
 
-		logger.Debug("Creating Flow Instance: ", instanceID)
-		logger.Debugf("Creating Flow Instance [%s] for event id [%s] ", instanceID, trigger.GetHandlerEventIdFromContext(ctx))
+		logger.Debug("Creating Flow Instance: ", logIDOf(execOptions, instanceID))
+		logger.Debugf("Creating Flow Instance [%s] for event id [%s] ", logIDOf(execOptions, instanceID), trigger.GetHandlerEventIdFromContext(ctx))
+
+		if correlationID == "" {
+			correlationID = idGenerator.NextAsString()
+		}
 
 		instLogger := logger
 
 		if log.CtxLoggingEnabled() {
-			instLogger = log.ChildLoggerWithFields(logger, log.FieldString("flowName", flowDef.Name()), log.FieldString("flowId", instanceID), log.FieldString("eventId", trigger.GetHandlerEventIdFromContext(ctx)))
+			instLogger = log.ChildLoggerWithFields(logger, log.FieldString("flowName", flowDef.Name()), log.FieldString("flowId", logIDOf(execOptions, instanceID)), log.FieldString("eventId", trigger.GetHandlerEventIdFromContext(ctx)), log.FieldString("correlationId", correlationID))
 		}
 
-		inst, err = instance.NewIndependentInstance(instanceID, flowURI, flowDef, instance.NewStateInstanceRecorder(stateRecorder, stateRecordingMode, rerun), instLogger)
+		inst, err = instance.NewIndependentInstance(instanceID, flowURI, flowDef, instance.NewStateInstanceRecorder(recorder, recordingMode, rerun), instLogger)
 		if err != nil {
 			return err
 		}
+		inst.SetCorrelationID(correlationID)
 	case instance.OpRestart:
 		if initialState != nil {
 
@@ -264,13 +705,21 @@ func (fa *FlowAction) Run(ctx context.Context, inputs map[string]interface{}, ha
 				instanceID = idGenerator.NextAsString()
 			}
 
-			logger.Debug("Restarting Flow Instance: ", instanceID)
+			logger.Debug("Restarting Flow Instance: ", logIDOf(execOptions, instanceID))
+
+			if correlationID == "" {
+				correlationID = inst.CorrelationID()
+			}
+			if correlationID == "" {
+				correlationID = idGenerator.NextAsString()
+			}
+			inst.SetCorrelationID(correlationID)
 
 			instLogger := logger
 			if log.CtxLoggingEnabled() {
-				instLogger = log.ChildLoggerWithFields(logger, log.FieldString("flowName", inst.Name()), log.FieldString("flowId", instanceID))
+				instLogger = log.ChildLoggerWithFields(logger, log.FieldString("flowName", inst.Name()), log.FieldString("flowId", logIDOf(execOptions, instanceID)), log.FieldString("correlationId", correlationID))
 			}
-			inst.SetInstanceRecorder(instance.NewStateInstanceRecorder(stateRecorder, stateRecordingMode, rerun))
+			inst.SetInstanceRecorder(instance.NewStateInstanceRecorder(recorder, recordingMode, rerun))
 			//Engine should set init step id one step before current restart step
 			err := inst.Restart(instLogger, instanceID, initStepId-1)
 			if err != nil {
@@ -285,6 +734,19 @@ func (fa *FlowAction) Run(ctx context.Context, inputs map[string]interface{}, ha
 			inst = initialState
 			logger.Debug("Resuming Flow Instance: ", inst.ID())
 
+			if correlationID != "" {
+				inst.SetCorrelationID(correlationID)
+			}
+
+			if resumeDef != nil {
+				if err := inst.UpdateDefinition(resumeDef); err != nil {
+					return fmt.Errorf("unable to resume instance [%s] with new definition: %s", inst.ID(), err.Error())
+				}
+			}
+
+			// an instance paused on a task error retries the failed task
+			inst.RetryPausedTask()
+
 			//instLogger := logger
 			//
 			//if log.CtxLoggingEnabled() {
@@ -296,32 +758,77 @@ func (fa *FlowAction) Run(ctx context.Context, inputs map[string]interface{}, ha
 		}
 	}
 
+	ri, err := registerInstance(inst, flowURI, idPolicy)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if !started {
+			discardInstance(ri)
+		}
+	}()
+
+	if op != instance.OpStart && len(attrOverrides) > 0 {
+		if err := inst.ValidateAttrs(attrOverrides); err != nil {
+			return err
+		}
+	}
+
+	inst.SetLabels(labels)
+
 	if execOptions != nil {
 		logger.Debugf("Applying Exec Options to instance: %s", inst.ID())
 		instance.ApplyExecOptions(inst, execOptions)
 	}
+	maxDuration, usingDefaultTimeout := resolveTimeout(execOptions, fa.defaultTimeout)
+
 	//Update flow starting time
 	inst.UpdateStartTime()
-	if stateRecorder != nil {
-		stateRecorder.RecordStart(inst.GetFlowState(inputs))
+	if recorder != nil {
+		if err := recorder.RecordStart(inst.GetFlowState(inputs)); err != nil {
+			if failOnRecorderError {
+				return fmt.Errorf("unable to record start of Flow Instance [%s]: %s", inst.ID(), err.Error())
+			}
+			logger.Errorf("Unable to record start of Flow Instance [%s]: %v", inst.LogID(), err)
+		}
 	}
 
-	if trace.Enabled() {
-		tc, err := trace.GetTracer().StartTrace(inst.SpanConfig(), trace.ExtractTracingContext(ctx))
+	if trace.Enabled() && !sampleTrace(flowURI, inputs, subflowOptions) {
+		inst.SetTraceSampled(false)
+	} else if trace.Enabled() {
+		spanConfig := inst.SpanConfig()
+		parentTc := tracingContextExtractor(ctx)
+		if subflowOptions != nil {
+			if subflowOptions.ParentTracingContext != nil {
+				parentTc = subflowOptions.ParentTracingContext
+			}
+			if subflowOptions.ParentInstanceID != "" {
+				spanConfig.Tags["parent_flow_id"] = subflowOptions.ParentInstanceID
+				spanConfig.Tags["parent_flow_name"] = subflowOptions.ParentFlowName
+			}
+		}
+
+		tc, err := trace.GetTracer().StartTrace(spanConfig, parentTc)
 		if err != nil {
 			return err
 		}
 		inst.SetTracingContext(tc)
 	}
 
-	//todo how do we check if debug is enabled?
-	//logInputs(inputs)
-	logger.Infof("Executing Flow Instance [%s] for event id [%s]", inst.ID(), trigger.GetHandlerEventIdFromContext(ctx))
+	logData(inst, "Flow Instance inputs", "inputs", inputs)
+	logInstance := sampleInstanceLog()
+	if logInstance {
+		logger.Infof("Executing Flow Instance [%s] for event id [%s]", inst.LogID(), trigger.GetHandlerEventIdFromContext(ctx))
+	}
+
+	inputs = normalizeNumbers(inputs, numberMode)
 
 	if op == instance.OpStart {
+		inst.SetRetryCount(retryCount)
 		inst.Start(inputs)
 	} else {
 		inst.UpdateAttrs(inputs)
+		inst.UpdateAttrs(attrOverrides)
 	}
 
 	//initStepId cannot less than 1. restart must start with 1 to xxxx
@@ -329,11 +836,50 @@ func (fa *FlowAction) Run(ctx context.Context, inputs map[string]interface{}, ha
 	if initStepId > 0 {
 		stepCount = initStepId - 1
 	}
+	stepLimit := fa.stepLimit(flowURI)
 
 	hasWork := true
 
+	// the context provided to activities lives as long as the instance, it keeps the values of the
+	// trigger's context but isn't cancelled with it, ex. once an asynchronous trigger has replied.
+	// The maximum duration is also its deadline, an activity that ignores it is only stopped at the
+	// next step boundary.
+	var instCtx context.Context
+	var cancelInstCtx context.CancelFunc
+	if maxDuration > 0 {
+		instCtx, cancelInstCtx = context.WithDeadline(detachedContext{ctx}, time.Now().Add(maxDuration))
+	} else {
+		instCtx, cancelInstCtx = context.WithCancel(detachedContext{ctx})
+	}
+	inst.SetGoContext(instCtx)
+	ri.setCancelCtx(cancelInstCtx)
+
+	// a retry delivers its results to the caller's handler, it is wrapped again by the retry
+	callerHandler := handler
+	var retryHandler *retryResultHandler
+	if retryInputs != nil {
+		retryHandler = &retryResultHandler{handler: handler}
+		handler = retryHandler
+	}
+
+	if execOptions != nil && (execOptions.ResultBufferSize > 0 || execOptions.ResultTimeout > 0) {
+		handler = newBufferedResultHandler(handler, execOptions.ResultBufferSize, execOptions.ResultTimeout, logger)
+	}
+	if execOptions != nil && execOptions.Envelope {
+		handler = newEnvelopeResultHandler(handler, inst, func() int { return stepCount })
+	}
+
+	// a fire and forget instance only returns its ID, its results are only recorded.  The ID is
+	// delivered as is to the caller's handler, it isn't buffered or wrapped in an envelope.  A retry
+	// doesn't return the ID again, the caller already has it.
+	idHandler := callerHandler
+	if execOptions != nil && execOptions.FireAndForget {
+		retID = retryCount == 0
+		handler = &discardResultHandler{handler: handler}
+	}
+
 	inst.SetResultHandler(handler)
-	if stateRecorder != nil {
+	if recorder != nil {
 		//We don't need record step 0 if restart from activity
 		if initStepId <= 0 {
 			inst.RecordState(time.Now().UTC())
@@ -343,54 +889,257 @@ func (fa *FlowAction) Run(ctx context.Context, inputs map[string]interface{}, ha
 		}
 	}
 
-	go func() {
-
-		defer handler.Done()
+	// execute runs the steps of the instance, it is re-entered by the scheduler when the
+	// instance has been delayed
+	step := stepChain()
+	resumed := op == instance.OpResume
 
-		if retID {
+	releaseSlot := func() {}
 
-			results := map[string]interface{}{
-				"id": inst.ID(),
-			}
+	// acquireSlot waits for the instance to be allowed to run, failing it if it gives up waiting
+	acquireSlot := func(ctx context.Context) {
+		if release, err := instanceLimiter.acquire(ctx, maxConcurrentInstances, priority); err != nil {
+			inst.Fail(fmt.Errorf("flow instance [%s] gave up waiting to run: %s", inst.ID(), err.Error()))
+		} else {
+			releaseSlot = release
+		}
+	}
 
-			handler.HandleResult(results, nil)
+	// retry schedules a new attempt of the failed flow, if it should be retried
+	retry := func(err error) bool {
+		if retryInputs == nil || !shouldRetryFlow(execOptions.FlowRetry, retryCount, err) {
+			return false
 		}
+		ro := &instance.RunOptions{Op: instance.OpStart, FlowURI: flowURI, ExecOptions: execOptions,
+			SubflowOptions: subflowOptions, Labels: labels, RetryCount: retryCount + 1, CorrelationID: inst.CorrelationID()}
+		if !fa.retryFlow(ctx, inst, ro, retryInputs, callerHandler) {
+			return false
+		}
+		retryHandler.retried = true
+		return true
+	}
 
-		for hasWork && inst.Status() < model.FlowStatusCompleted && stepCount < maxStepCount {
+	var execute func()
+	execute = func() {
+		var err error
+		for hasWork && inst.Status() < model.FlowStatusCompleted && stepCount < stepLimit {
 			stepCount++
 			logger.Debugf("Step: %d", stepCount)
+			if maxDuration > 0 && inst.ExecutionTime() > maxDuration {
+				if usingDefaultTimeout {
+					logger.Warnf("Flow Instance [%s] exceeded the default flow timeout of %s", inst.LogID(), maxDuration)
+				}
+				inst.Fail(fmt.Errorf("flow instance [%s] exceeded its maximum duration of %s", inst.ID(), maxDuration))
+				break
+			}
+			ri.setStep(stepCount)
+			if stepGate != nil && !stepGate(inst.ID(), stepCount, inst.NextTaskID()) {
+				logger.Infof("Flow Instance [%s] paused before step %d", inst.LogID(), stepCount)
+				ri.pause()
+			}
+			if ri.isCancelled() {
+				logger.Infof("Flow Instance [%s] cancelled", inst.LogID())
+				inst.Cancel()
+				break
+			}
+			if ri.checkpointRequested() {
+				logger.Infof("Flow Instance [%s] paused for checkpoint", inst.LogID())
+				inst.Suspend(inst.ID())
+				break
+			}
+			// an instance resumed at a pause point continues with the task it paused at
+			if !resumed && isPausePoint(flowURI, inst.NextTaskID()) {
+				logger.Infof("Flow Instance [%s] paused at task [%s]", inst.LogID(), inst.NextTaskID())
+				inst.Suspend(inst.ID())
+				break
+			}
+			resumed = false
 			taskStartTime := time.Now().UTC()
-			hasWork = inst.DoStep()
-			if stateRecorder != nil {
-				inst.RecordState(taskStartTime)
+			taskID := inst.NextTaskID()
+			ri.stepMu.Lock()
+			hasWork, err = step(inst)
+			if err != nil {
+				hasWork = inst.HandleStepError(taskID, err)
+			}
+			ri.stepMu.Unlock()
+			ri.setState(inst.Status(), inst.Labels())
+			if err != nil && !hasWork {
+				break
+			}
+			if (trackInstanceMemory || maxInstanceMemory > 0) && !checkInstanceMemory(ri) {
+				break
+			}
+			if recorder != nil {
+				if err := inst.RecordState(taskStartTime); err != nil {
+					inst.Fail(err)
+					break
+				}
+			}
+
+			if inst.SuspendToken() != "" || !inst.DelayUntil().IsZero() {
+				break
+			}
+		}
+
+		if stepCount >= stepLimit && hasWork && inst.Status() < model.FlowStatusCompleted && inst.SuspendToken() == "" && inst.DelayUntil().IsZero() {
+			inst.Fail(fmt.Errorf("flow instance [%s] exceeded its maximum of %d steps, it may be in a runaway loop", inst.ID(), stepLimit))
+		}
+
+		if at := inst.DelayUntil(); !at.IsZero() {
+			inst.ClearDelay()
+			// the slot isn't held while the instance is delayed, it waits for one again when the
+			// delay is over, even if the caller has gone by then
+			releaseSlot()
+			releaseSlot = func() {}
+			err := scheduler.Schedule(inst.ID(), at, func() {
+				acquireSlot(detachedContext{ctx})
+				execute()
+			})
+			if err == nil {
+				logger.Infof("Flow Instance [%s] delayed until %s", inst.LogID(), at.UTC())
+				return
+			}
+			inst.Fail(fmt.Errorf("unable to schedule delayed flow instance [%s]: %v", inst.ID(), err))
+		}
+
+		// finish records the final state of the instance and removes it from the running instances
+		finish := func() {
+			ri.setState(inst.Status(), inst.Labels())
+			unregisterInstance(ri)
+		}
+		suspended := false
+		defer handler.Done()
+		defer func() {
+			if !suspended {
+				finish()
+			}
+		}()
+		defer cancelInstCtx()
+		defer releaseSlot()
+
+		traced := false
+		if token := inst.SuspendToken(); token != "" {
+			// the instance can be resumed as soon as it is saved, it is recorded, traced and
+			// finished first and saving it is the last thing done with it
+			if recorder != nil {
+				if err := recorder.RecordSnapshot(inst.Snapshot()); err != nil {
+					logger.Warnf("Unable to record snapshot of suspended Flow Instance [%s]: %v", inst.LogID(), err)
+				}
 			}
+			finishTrace(inst, stepCount, FlowStatusSuspended, inputs, nil, nil)
+			traced = true
+			logID := inst.LogID()
+			finish()
+
+			err := suspendInstance(token, inst)
+			if err == nil {
+				suspended = true
+				countInstance(FlowStatusSuspended)
+				logger.Infof("Flow Instance [%s] suspended with token [%s]", logID, token)
+				handler.HandleResult(withFlowStatus(map[string]interface{}{SuspendTokenKey: token}, FlowStatusSuspended), nil)
+				return
+			}
+			// the instance couldn't be saved, it can't be resumed
+			inst.ClearSuspend()
+			inst.Fail(fmt.Errorf("unable to save flow instance [%s] suspended with token [%s]: %s", inst.ID(), token, err.Error()))
 		}
 
 		if inst.Status() == model.FlowStatusCompleted {
-			returnData, err := inst.GetReturnData()
-			if inst.TracingContext() != nil {
-				_ = trace.GetTracer().FinishTrace(inst.TracingContext(), nil)
+			var returnData map[string]interface{}
+			var err error
+			if _, hasDeadline := ctx.Deadline(); hasDeadline {
+				returnData, err = inst.GetReturnDataContext(ctx)
+			} else {
+				returnData, err = inst.GetReturnData()
+			}
+			if err == nil && fa.validateOutputs {
+				err = validateOutputs(flowURI, inst.FlowDefinition().Metadata(), returnData)
+			}
+			if err == nil && fa.outputMapper != nil {
+				returnData, err = applyOutputMapper(fa.outputMapper, flowURI, returnData)
+			}
+			returnData = normalizeNumbers(returnData, numberMode)
+			if err == nil && maxOutputBytes > 0 {
+				if err = checkOutputSize(inst.ID(), returnData, maxOutputBytes); err != nil {
+					returnData = nil
+				}
+			}
+			status := FlowStatusCompleted
+			if err != nil {
+				status = FlowStatusFailed
+			}
+			// an error returned by the flow (see ErrorWithCode) is retried like a failure
+			retried := false
+			if _, returned := err.(*instance.ErrorWithCode); returned {
+				retried = retry(err)
+			}
+			finishTrace(inst, stepCount, status, inputs, returnData, err)
+			countInstance(status)
+			logData(inst, "Flow Instance outputs", "outputs", returnData)
+			if instanceRetention > 0 {
+				ri.setOutputs(returnData)
+			}
+			if cacheKey != "" && err == nil {
+				cacheResults(cacheKey, returnData, fa.cacheTTL)
+			}
+			if err == nil {
+				if pubErr := completionPublisher.Publish(inst.ID(), inst.Name(), returnData); pubErr != nil {
+					logger.Warnf("Unable to publish results of Flow Instance [%s]: %v", inst.LogID(), pubErr)
+				}
+			}
+			if !retried {
+				handler.HandleResult(withFlowStatus(returnData, status), err)
 			}
-			handler.HandleResult(returnData, err)
 		} else if inst.Status() == model.FlowStatusFailed {
-			if inst.TracingContext() != nil {
-				_ = trace.GetTracer().FinishTrace(inst.TracingContext(), inst.GetError())
+			if !traced {
+				finishTrace(inst, stepCount, FlowStatusFailed, inputs, nil, inst.GetError())
 			}
-			handler.HandleResult(nil, inst.GetError())
+			countInstance(FlowStatusFailed)
+			if !retry(inst.GetError()) {
+				handler.HandleResult(withFlowStatus(nil, FlowStatusFailed), inst.GetError())
+			}
+		} else if inst.Status() == model.FlowStatusCancelled {
+			finishTrace(inst, stepCount, FlowStatusCancelled, inputs, nil, inst.GetError())
+			countInstance(FlowStatusCancelled)
+			handler.HandleResult(withFlowStatus(nil, FlowStatusCancelled), inst.GetError())
 		}
 
-		logger.Debugf("Executing flow instance [%s] for event id [%s] - Status: %d", inst.ID(), trigger.GetHandlerEventIdFromContext(ctx), inst.Status())
+		logger.Debugf("Executing flow instance [%s] for event id [%s] - Status: %d", inst.LogID(), trigger.GetHandlerEventIdFromContext(ctx), inst.Status())
 
-		if inst.Status() == model.FlowStatusCompleted {
-			logger.Infof("Flow Instance [%s] for event id [%s] completed in %s", inst.ID(), trigger.GetHandlerEventIdFromContext(ctx), inst.ExecutionTime().String())
+		recordInstanceMetrics(inst)
+
+		if inst.Status() == model.FlowStatusCompleted && logInstance {
+			logger.Infof("Flow Instance [%s] for event id [%s] completed in %s", inst.LogID(), trigger.GetHandlerEventIdFromContext(ctx), inst.ExecutionTime().String())
 		} else if inst.Status() == model.FlowStatusFailed {
-			logger.Infof("Flow Instance [%s] for event id [%s] failed in %s", inst.ID(), trigger.GetHandlerEventIdFromContext(ctx), inst.ExecutionTime().String())
+			logger.Infof("Flow Instance [%s] for event id [%s] failed in %s", inst.LogID(), trigger.GetHandlerEventIdFromContext(ctx), inst.ExecutionTime().String())
 		}
 
-		if stateRecorder != nil {
-			stateRecorder.RecordDone(inst.GetFlowState(inputs))
+		if recorder != nil {
+			if err := recorder.RecordDone(inst.GetFlowState(inputs)); err != nil {
+				logger.Errorf("Unable to record completion of Flow Instance [%s]: %v", inst.LogID(), err)
+			}
+		}
+	}
+
+	ri.setState(inst.Status(), inst.Labels())
+	started = true
+	go func() {
+		if retID {
+
+			results := map[string]interface{}{
+				"id": inst.ID(),
+			}
+
+			idHandler.HandleResult(results, nil)
+		}
+
+		if startRelease != nil {
+			releaseSlot = startRelease
+		} else {
+			acquireSlot(ctx)
 		}
 
+		execute()
 	}()
 
 	return nil
@@ -0,0 +1,52 @@
+package flow
+
+import (
+	"sync"
+)
+
+// AdmissionController decides whether a flow is allowed to start, ex. to enforce rate limits or
+// tenant quotas.  A non-nil error rejects the start before any work is done, the error is
+// returned by FlowAction.Run.
+type AdmissionController interface {
+	Admit(flowURI string, inputs map[string]interface{}) error
+}
+
+// AdmissionFunc is an adapter to allow the use of ordinary functions as an AdmissionController
+type AdmissionFunc func(flowURI string, inputs map[string]interface{}) error
+
+func (f AdmissionFunc) Admit(flowURI string, inputs map[string]interface{}) error {
+	return f(flowURI, inputs)
+}
+
+var (
+	admissionMu          sync.RWMutex // protects the admission controllers
+	admissionControllers []AdmissionController
+)
+
+// AddAdmissionController adds an AdmissionController, the controllers are consulted in the order
+// they were added and the first rejection stops the flow from starting
+func AddAdmissionController(controller AdmissionController) {
+	admissionMu.Lock()
+	admissionControllers = append(admissionControllers, controller)
+	admissionMu.Unlock()
+}
+
+// ClearAdmissionControllers removes all the AdmissionControllers
+func ClearAdmissionControllers() {
+	admissionMu.Lock()
+	admissionControllers = nil
+	admissionMu.Unlock()
+}
+
+// admit consults the admission controllers, returning the first rejection
+func admit(flowURI string, inputs map[string]interface{}) error {
+	admissionMu.RLock()
+	defer admissionMu.RUnlock()
+
+	for _, controller := range admissionControllers {
+		if err := controller.Admit(flowURI, inputs); err != nil {
+			return err
+		}
+	}
+	return nil
+}
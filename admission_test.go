@@ -0,0 +1,33 @@
+package flow
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAdmit(t *testing.T) {
+	defer ClearAdmissionControllers()
+
+	assert.Nil(t, admit("res://flow:orders", nil))
+
+	calls := 0
+	AddAdmissionController(AdmissionFunc(func(flowURI string, inputs map[string]interface{}) error {
+		calls++
+		if inputs["tenant"] == "blocked" {
+			return errors.New("tenant quota exceeded")
+		}
+		return nil
+	}))
+	AddAdmissionController(AdmissionFunc(func(flowURI string, inputs map[string]interface{}) error {
+		calls++
+		return nil
+	}))
+
+	assert.Nil(t, admit("res://flow:orders", map[string]interface{}{"tenant": "acme"}))
+	assert.Equal(t, 2, calls)
+
+	assert.NotNil(t, admit("res://flow:orders", map[string]interface{}{"tenant": "blocked"}))
+	assert.Equal(t, 3, calls)
+}
@@ -0,0 +1,43 @@
+package flow
+
+import (
+	"context"
+)
+
+// Result is the final result of a flow run using RunAsync
+type Result struct {
+	Data   map[string]interface{}
+	Err    error
+	Status string
+}
+
+// RunAsync runs the flow, returning a channel that delivers its final result once the instance
+// is done.  It is an alternative to providing an action.ResultHandler for Go callers.
+func (fa *FlowAction) RunAsync(ctx context.Context, inputs map[string]interface{}) (<-chan *Result, error) {
+	handler := &channelResultHandler{results: make(chan *Result, 1)}
+
+	if err := fa.Run(ctx, inputs, handler); err != nil {
+		return nil, err
+	}
+
+	return handler.results, nil
+}
+
+// channelResultHandler adapts the result handler mechanism to a channel, only the last result
+// handled is delivered
+type channelResultHandler struct {
+	last    *Result
+	results chan *Result
+}
+
+func (h *channelResultHandler) HandleResult(results map[string]interface{}, err error) {
+	status, _ := results[FlowStatusKey].(string)
+	h.last = &Result{Data: results, Err: err, Status: status}
+}
+
+func (h *channelResultHandler) Done() {
+	if h.last != nil {
+		h.results <- h.last
+	}
+	close(h.results)
+}
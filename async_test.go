@@ -0,0 +1,23 @@
+package flow
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChannelResultHandler(t *testing.T) {
+	handler := &channelResultHandler{results: make(chan *Result, 1)}
+
+	handler.HandleResult(map[string]interface{}{"id": "1"}, nil)
+	handler.HandleResult(withFlowStatus(nil, FlowStatusFailed), errors.New("failed"))
+	handler.Done()
+
+	result := <-handler.results
+	assert.Equal(t, FlowStatusFailed, result.Status)
+	assert.NotNil(t, result.Err)
+
+	_, ok := <-handler.results
+	assert.False(t, ok)
+}
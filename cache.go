@@ -0,0 +1,188 @@
+package flow
+
+import (
+	"crypto/sha256"
+	"encoding"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/project-flogo/core/action"
+	"github.com/project-flogo/flow/definition"
+	"github.com/project-flogo/flow/util"
+)
+
+// ResultCache caches the results of cacheable flows (see Settings.Cacheable), keyed by flow and
+// inputs.  Keys are prefixed by the URI of the flow, followed by '#'.
+type ResultCache interface {
+	Get(key string) (map[string]interface{}, bool)
+	Set(key string, results map[string]interface{}, ttl time.Duration)
+	// Invalidate removes all the cached results of the flow
+	Invalidate(flowURI string)
+}
+
+// NewMemoryResultCache creates an in-process ResultCache, this is the default
+func NewMemoryResultCache() ResultCache {
+	return &memoryResultCache{entries: make(map[string]*cachedResult)}
+}
+
+type cachedResult struct {
+	results map[string]interface{}
+	expires time.Time
+}
+
+type memoryResultCache struct {
+	mu      sync.Mutex
+	entries map[string]*cachedResult
+}
+
+func (c *memoryResultCache) Get(key string) (map[string]interface{}, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, exists := c.entries[key]
+	if !exists {
+		return nil, false
+	}
+	if time.Now().After(entry.expires) {
+		delete(c.entries, key)
+		return nil, false
+	}
+	return entry.results, true
+}
+
+func (c *memoryResultCache) Set(key string, results map[string]interface{}, ttl time.Duration) {
+	c.mu.Lock()
+	c.entries[key] = &cachedResult{results: results, expires: time.Now().Add(ttl)}
+	c.mu.Unlock()
+}
+
+func (c *memoryResultCache) Invalidate(flowURI string) {
+	prefix := flowURI + "#"
+
+	c.mu.Lock()
+	for key := range c.entries {
+		if strings.HasPrefix(key, prefix) {
+			delete(c.entries, key)
+		}
+	}
+	c.mu.Unlock()
+}
+
+var resultCache = NewMemoryResultCache()
+
+// SetResultCache sets the ResultCache used for cacheable flows, nil restores the default
+// in-process cache
+func SetResultCache(cache ResultCache) {
+	if cache == nil {
+		cache = NewMemoryResultCache()
+	}
+	resultCache = cache
+}
+
+// resultCacheKey computes the cache key of the inputs of the flow.  The key includes the hash of
+// the content of the definition, so a reloaded definition only shares cached results with the
+// definition it replaces if they're identical.
+func resultCacheKey(flowURI string, def *definition.Definition, inputs map[string]interface{}) (string, error) {
+	if def.Hash() == "" {
+		return "", fmt.Errorf("definition of flow [%s] couldn't be hashed", flowURI)
+	}
+
+	// maps are marshalled with sorted keys, so equivalent inputs have the same hash
+	data, err := json.Marshal(inputs)
+	if err != nil {
+		return "", err
+	}
+	for name, value := range inputs {
+		if !isHashable(reflect.ValueOf(value)) {
+			return "", fmt.Errorf("input '%s' of type %T can't be marshalled faithfully", name, value)
+		}
+	}
+
+	hash := sha256.New()
+	_, _ = hash.Write([]byte(def.Hash()))
+	_, _ = hash.Write(data)
+	return flowURI + "#" + hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+var (
+	jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+	readerType        = reflect.TypeOf((*io.Reader)(nil)).Elem()
+)
+
+// isHashable returns true if the JSON encoding of the value identifies it, which isn't the case of
+// streams or values without exported fields (ex. an io.Reader is marshalled as {})
+func isHashable(value reflect.Value) bool {
+	if !value.IsValid() {
+		return true
+	}
+
+	t := value.Type()
+	if t.Implements(readerType) {
+		return false
+	}
+	if t.Implements(jsonMarshalerType) || t.Implements(textMarshalerType) {
+		return true
+	}
+
+	switch value.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		return value.IsNil() || isHashable(value.Elem())
+	case reflect.Map:
+		iter := value.MapRange()
+		for iter.Next() {
+			if !isHashable(iter.Value()) {
+				return false
+			}
+		}
+		return true
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < value.Len(); i++ {
+			if !isHashable(value.Index(i)) {
+				return false
+			}
+		}
+		return true
+	case reflect.Struct:
+		exported := 0
+		for i := 0; i < t.NumField(); i++ {
+			if t.Field(i).PkgPath != "" {
+				continue
+			}
+			exported++
+			if !isHashable(value.Field(i)) {
+				return false
+			}
+		}
+		return exported > 0 || t.NumField() == 0
+	default:
+		return true
+	}
+}
+
+// cachedResults delivers the cached results of the flow, if any, returning false if there are none
+func cachedResults(key string, handler action.ResultHandler) bool {
+	results, exists := resultCache.Get(key)
+	if !exists {
+		return false
+	}
+
+	results = util.DeepCopyMap(results)
+	go func() {
+		defer handler.Done()
+		handler.HandleResult(withFlowStatus(results, FlowStatusCompleted), nil)
+	}()
+
+	return true
+}
+
+// cacheResults caches the results of the flow
+func cacheResults(key string, results map[string]interface{}, ttl time.Duration) {
+	resultCache.Set(key, util.DeepCopyMap(results), ttl)
+}
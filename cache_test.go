@@ -0,0 +1,66 @@
+package flow
+
+import (
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/project-flogo/flow/definition"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResultCacheKey(t *testing.T) {
+	def := newTestDefinition(t, "pure")
+
+	key1, err := resultCacheKey("res://flow:pure", def, map[string]interface{}{"a": 1, "b": "x"})
+	assert.Nil(t, err)
+	key2, _ := resultCacheKey("res://flow:pure", def, map[string]interface{}{"b": "x", "a": 1})
+	assert.Equal(t, key1, key2)
+
+	key3, _ := resultCacheKey("res://flow:pure", def, map[string]interface{}{"a": 2, "b": "x"})
+	assert.NotEqual(t, key1, key3)
+
+	// a reloaded definition with the same content shares the cached results
+	key4, _ := resultCacheKey("res://flow:pure", newTestDefinition(t, "pure"), map[string]interface{}{"a": 1, "b": "x"})
+	assert.Equal(t, key1, key4)
+	key5, _ := resultCacheKey("res://flow:pure", newTestDefinition(t, "changed"), map[string]interface{}{"a": 1, "b": "x"})
+	assert.NotEqual(t, key1, key5)
+
+	_, err = resultCacheKey("res://flow:pure", &definition.Definition{}, nil)
+	assert.NotNil(t, err)
+}
+
+func TestResultCacheKeyUnhashableInputs(t *testing.T) {
+	def := newTestDefinition(t, "pure")
+
+	_, err := resultCacheKey("res://flow:pure", def, map[string]interface{}{"body": strings.NewReader("x")})
+	assert.NotNil(t, err)
+	_, err = resultCacheKey("res://flow:pure", def, map[string]interface{}{"items": []interface{}{&sync.Mutex{}}})
+	assert.NotNil(t, err)
+
+	_, err = resultCacheKey("res://flow:pure", def, map[string]interface{}{
+		"at":    time.Now(),
+		"items": []interface{}{map[string]interface{}{"a": 1}, nil},
+		"data":  []byte("x"),
+		"point": struct{ X int }{1},
+	})
+	assert.Nil(t, err)
+}
+
+func TestCachedResults(t *testing.T) {
+	defer SetResultCache(nil)
+
+	key, _ := resultCacheKey("res://flow:pure", newTestDefinition(t, "pure"), nil)
+	handler := &testResultHandler{done: make(chan struct{})}
+	assert.False(t, cachedResults(key, handler))
+
+	cacheResults(key, map[string]interface{}{"result": 42}, time.Minute)
+	assert.True(t, cachedResults(key, handler))
+	<-handler.done
+	assert.Equal(t, 42, handler.results["result"])
+	assert.Equal(t, FlowStatusCompleted, handler.results[FlowStatusKey])
+
+	resultCache.Invalidate("res://flow:pure")
+	assert.False(t, cachedResults(key, &testResultHandler{done: make(chan struct{})}))
+}
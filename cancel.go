@@ -0,0 +1,54 @@
+package flow
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/project-flogo/flow/state"
+)
+
+// CancelledError is returned when an instance whose cancellation was requested is resumed
+type CancelledError struct {
+	ID          string
+	RequestedAt time.Time
+}
+
+func (e *CancelledError) Error() string {
+	return fmt.Sprintf("flow instance [%s] was cancelled at %s", e.ID, e.RequestedAt.Format(time.RFC3339))
+}
+
+// RequestCancel requests the cancellation of the instance with the specified ID.  A cancel marker
+// (see state.CancelMarker) is written to the state recorder, which must implement
+// state.CancelRecorder, and the instance is cancelled if it is running.  An instance that is
+// suspended, or that gets suspended before the cancel reaches it, is aborted when it is resumed.
+func RequestCancel(instanceID string) error {
+	if stateRecorder == nil {
+		return fmt.Errorf("unable to cancel instance [%s], state recording is not enabled", instanceID)
+	}
+
+	marker := &state.CancelMarker{FlowID: instanceID, RequestedAt: time.Now().UTC()}
+	if err := state.RecordCancel(stateRecorder, marker); err != nil {
+		return fmt.Errorf("unable to cancel instance [%s]: %s", instanceID, err.Error())
+	}
+
+	if ri := getRunningInstance(instanceID); ri != nil {
+		ri.cancel()
+	}
+	return nil
+}
+
+// checkCancelled returns a CancelledError if a cancel marker was recorded for the instance
+func checkCancelled(instanceID string) error {
+	if stateRecorder == nil {
+		return nil
+	}
+
+	marker, err := state.GetCancel(stateRecorder, instanceID)
+	if err != nil {
+		return fmt.Errorf("unable to check cancellation of instance [%s]: %s", instanceID, err.Error())
+	}
+	if marker != nil {
+		return &CancelledError{ID: instanceID, RequestedAt: marker.RequestedAt}
+	}
+	return nil
+}
@@ -0,0 +1,48 @@
+package flow
+
+import (
+	"testing"
+
+	"github.com/project-flogo/flow/state"
+	"github.com/stretchr/testify/assert"
+)
+
+type cancelRecorder struct {
+	historyRecorder
+	markers map[string]*state.CancelMarker
+}
+
+func (r *cancelRecorder) RecordCancel(marker *state.CancelMarker) error {
+	r.markers[marker.FlowID] = marker
+	return nil
+}
+
+func (r *cancelRecorder) GetCancel(flowID string) (*state.CancelMarker, error) {
+	return r.markers[flowID], nil
+}
+
+func TestRequestCancel(t *testing.T) {
+	prev := stateRecorder
+	defer func() { stateRecorder = prev }()
+
+	stateRecorder = nil
+	assert.NotNil(t, RequestCancel("cancel-1"))
+	assert.Nil(t, checkCancelled("cancel-1"))
+
+	// the recorder must support cancel markers
+	stateRecorder = &historyRecorder{}
+	assert.NotNil(t, RequestCancel("cancel-1"))
+	assert.Nil(t, checkCancelled("cancel-1"))
+
+	recorder := &cancelRecorder{markers: make(map[string]*state.CancelMarker)}
+	stateRecorder = recorder
+	assert.Nil(t, checkCancelled("cancel-1"))
+
+	assert.Nil(t, RequestCancel("cancel-1"))
+	assert.Contains(t, recorder.markers, "cancel-1")
+
+	err := checkCancelled("cancel-1")
+	assert.IsType(t, &CancelledError{}, err)
+	assert.Equal(t, "cancel-1", err.(*CancelledError).ID)
+	assert.Nil(t, checkCancelled("cancel-2"))
+}
@@ -0,0 +1,50 @@
+package flow
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/project-flogo/core/action"
+	"github.com/project-flogo/flow/instance"
+)
+
+// RestartFromCheckpoint runs the instance with the specified ID again from the named checkpoint
+// created by one of its activities (see instance.CheckpointContext), using the snapshot recorded
+// for the checkpoint with the overrides applied (see RunOptions.AttrOverrides).  The instance is run
+// as a new instance whose ID is returned, its results are delivered to the handler.  Checkpoints
+// created while a subflow was running can't be restarted from.
+func (fa *FlowAction) RestartFromCheckpoint(ctx context.Context, instanceID, checkpoint string, overrides map[string]interface{}, handler action.ResultHandler) (string, error) {
+	ro, err := checkpointOptions(instanceID, checkpoint, overrides)
+	if err != nil {
+		return "", err
+	}
+
+	ro.PreservedInstanceId = idGenerator.NextAsString()
+	inputs := map[string]interface{}{"_run_options": ro}
+
+	if err := fa.Run(ctx, inputs, handler); err != nil {
+		return "", err
+	}
+	return ro.PreservedInstanceId, nil
+}
+
+// checkpointOptions builds the options to restart the instance from the snapshot recorded for the
+// checkpoint
+func checkpointOptions(instanceID, checkpoint string, overrides map[string]interface{}) (*instance.RunOptions, error) {
+	snapshot, err := GetInstanceCheckpoint(instanceID, checkpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	initialState, err := instanceFromSnapshot(snapshot, "")
+	if err != nil {
+		return nil, fmt.Errorf("unable to restart instance [%s] from checkpoint [%s]: %s", instanceID, checkpoint, err.Error())
+	}
+
+	return &instance.RunOptions{
+		Op:            instance.OpRestart,
+		FlowURI:       initialState.FlowURI(),
+		InitialState:  initialState,
+		AttrOverrides: overrides,
+	}, nil
+}
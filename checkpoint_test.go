@@ -0,0 +1,54 @@
+package flow
+
+import (
+	"testing"
+
+	"github.com/project-flogo/core/support/log"
+	"github.com/project-flogo/flow/instance"
+	"github.com/project-flogo/flow/state"
+	"github.com/stretchr/testify/assert"
+)
+
+type checkpointRecorder struct {
+	historyRecorder
+	checkpoints map[string]*state.Snapshot
+}
+
+func (r *checkpointRecorder) GetCheckpoint(flowID, name string) (*state.Snapshot, error) {
+	snapshot, exists := r.checkpoints[name]
+	if !exists {
+		return nil, state.ErrInstanceNotFound
+	}
+	return snapshot, nil
+}
+
+func TestCheckpointOptions(t *testing.T) {
+	inst := newSampledInstance(t, "checkpoint-1", &historyRecorder{})
+	inst.Start(nil)
+	inst.DoStep()
+	assert.Equal(t, "b", inst.NextTaskID())
+
+	snapshot := inst.Snapshot()
+	snapshot.Checkpoint = "afterA"
+	recorder := &checkpointRecorder{checkpoints: map[string]*state.Snapshot{"afterA": snapshot}}
+
+	prev := stateRecorder
+	stateRecorder = recorder
+	defer func() { stateRecorder = prev }()
+
+	_, err := checkpointOptions("checkpoint-1", "unknown", nil)
+	assert.Equal(t, state.ErrInstanceNotFound, err)
+
+	ro, err := checkpointOptions("checkpoint-1", "afterA", map[string]interface{}{"orderId": "2"})
+	assert.Nil(t, err)
+	assert.Equal(t, instance.OpRestart, ro.Op)
+	assert.Equal(t, sampledFlowURI, ro.FlowURI)
+	assert.False(t, ro.Rerun)
+
+	// the restarted instance continues after the checkpoint
+	restarted := ro.InitialState
+	assert.Nil(t, restarted.Restart(log.RootLogger(), "checkpoint-2", -1))
+	assert.Equal(t, "b", restarted.NextTaskID())
+	assert.True(t, restarted.DoStep())
+	assert.Equal(t, "c", restarted.NextTaskID())
+}
@@ -0,0 +1,137 @@
+package flow
+
+import (
+	"container/heap"
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrStartTimedOut is returned by Run when the instance isn't allowed to run within its
+// ExecOptions.StartTimeout, because the maximum number of concurrent instances is reached
+var ErrStartTimedOut = errors.New("flow start timed out waiting for a concurrency slot")
+
+// maxConcurrentInstances is the maximum number of instances executing at once, instances started
+// beyond the limit wait for a running instance to finish, in order of priority
+var maxConcurrentInstances int
+
+var instanceLimiter = &concurrencyLimiter{}
+
+// concurrencyLimiter bounds the number of running instances, waiting instances are admitted in
+// order of their priority (see RunOptions.Priority), and in arrival order for equal priorities
+type concurrencyLimiter struct {
+	mu      sync.Mutex
+	running int
+	seq     uint64
+	waiting waitQueue
+}
+
+type waiter struct {
+	priority int
+	seq      uint64
+	index    int
+	ready    chan struct{}
+}
+
+// acquire waits for the instance to be allowed to run, the returned function releases its slot
+func (l *concurrencyLimiter) acquire(ctx context.Context, max, priority int) (func(), error) {
+	if max <= 0 {
+		return func() {}, nil
+	}
+
+	l.mu.Lock()
+	if l.running < max && len(l.waiting) == 0 {
+		l.running++
+		l.mu.Unlock()
+		return l.releaser(), nil
+	}
+
+	l.seq++
+	w := &waiter{priority: priority, seq: l.seq, ready: make(chan struct{})}
+	heap.Push(&l.waiting, w)
+	l.mu.Unlock()
+
+	select {
+	case <-w.ready:
+		return l.releaser(), nil
+	case <-ctx.Done():
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		if w.index < 0 {
+			// the slot was granted while giving up, hand it on
+			l.release()
+		} else {
+			heap.Remove(&l.waiting, w.index)
+		}
+		return nil, ctx.Err()
+	}
+}
+
+// acquireWithin waits up to the timeout for the instance to be allowed to run, ErrStartTimedOut
+// is returned if it isn't
+func (l *concurrencyLimiter) acquireWithin(ctx context.Context, max, priority int, timeout time.Duration) (func(), error) {
+	waitCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	release, err := l.acquire(waitCtx, max, priority)
+	if err == context.DeadlineExceeded && ctx.Err() == nil {
+		return nil, ErrStartTimedOut
+	}
+	return release, err
+}
+
+func (l *concurrencyLimiter) releaser() func() {
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			l.mu.Lock()
+			l.release()
+			l.mu.Unlock()
+		})
+	}
+}
+
+// release gives the slot of a finished instance to the highest priority waiting instance
+func (l *concurrencyLimiter) release() {
+	if len(l.waiting) > 0 {
+		w := heap.Pop(&l.waiting).(*waiter)
+		close(w.ready)
+		return
+	}
+	l.running--
+}
+
+// waitQueue is a heap of waiting instances, ordered by descending priority
+type waitQueue []*waiter
+
+func (q waitQueue) Len() int { return len(q) }
+
+func (q waitQueue) Less(i, j int) bool {
+	if q[i].priority != q[j].priority {
+		return q[i].priority > q[j].priority
+	}
+	return q[i].seq < q[j].seq
+}
+
+func (q waitQueue) Swap(i, j int) {
+	q[i], q[j] = q[j], q[i]
+	q[i].index = i
+	q[j].index = j
+}
+
+func (q *waitQueue) Push(x interface{}) {
+	w := x.(*waiter)
+	w.index = len(*q)
+	*q = append(*q, w)
+}
+
+func (q *waitQueue) Pop() interface{} {
+	old := *q
+	n := len(old)
+	w := old[n-1]
+	old[n-1] = nil
+	w.index = -1
+	*q = old[:n-1]
+	return w
+}
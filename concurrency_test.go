@@ -0,0 +1,94 @@
+package flow
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// waitForQueued waits until n instances are waiting for the limiter
+func waitForQueued(l *concurrencyLimiter, n int) {
+	for {
+		l.mu.Lock()
+		queued := len(l.waiting)
+		l.mu.Unlock()
+		if queued >= n {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestConcurrencyLimiterPriority(t *testing.T) {
+	l := &concurrencyLimiter{}
+
+	release, err := l.acquire(context.Background(), 1, 0)
+	assert.Nil(t, err)
+
+	order := make(chan int, 3)
+	for i, priority := range []int{1, 5, 3} {
+		go func(priority int) {
+			r, err := l.acquire(context.Background(), 1, priority)
+			assert.Nil(t, err)
+			order <- priority
+			r()
+		}(priority)
+		waitForQueued(l, i+1)
+	}
+
+	release()
+	assert.Equal(t, 5, <-order)
+	assert.Equal(t, 3, <-order)
+	assert.Equal(t, 1, <-order)
+
+	l.mu.Lock()
+	assert.Equal(t, 0, l.running)
+	l.mu.Unlock()
+}
+
+func TestConcurrencyLimiterCancel(t *testing.T) {
+	l := &concurrencyLimiter{}
+
+	release, err := l.acquire(context.Background(), 1, 0)
+	assert.Nil(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err = l.acquire(ctx, 1, 0)
+	assert.NotNil(t, err)
+	assert.Empty(t, l.waiting)
+
+	release()
+	release()
+	assert.Equal(t, 0, l.running)
+
+	// no limit
+	_, err = l.acquire(context.Background(), 0, 0)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, l.running)
+}
+
+func TestConcurrencyLimiterAcquireWithin(t *testing.T) {
+	l := &concurrencyLimiter{}
+
+	release, err := l.acquireWithin(context.Background(), 1, 0, time.Second)
+	assert.Nil(t, err)
+
+	_, err = l.acquireWithin(context.Background(), 1, 0, 10*time.Millisecond)
+	assert.Equal(t, ErrStartTimedOut, err)
+	assert.Empty(t, l.waiting)
+
+	// a cancelled context isn't a start timeout
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = l.acquireWithin(ctx, 1, 0, time.Second)
+	assert.Equal(t, context.Canceled, err)
+
+	release()
+	release, err = l.acquireWithin(context.Background(), 1, 0, 10*time.Millisecond)
+	assert.Nil(t, err)
+	release()
+	assert.Equal(t, 0, l.running)
+}
@@ -0,0 +1,233 @@
+package flow
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+const (
+	// InputPayloadKey is the input holding a raw payload, []byte or string, that is decoded into the
+	// flow's inputs by the InputDecoder registered for its content type
+	InputPayloadKey = "_payload"
+	// InputContentTypeKey is the input holding the content type of the raw payload.  When it's
+	// not set the content type provided by the trigger (see ContextWithContentType) is used, the
+	// default is ContentTypeJSON.
+	InputContentTypeKey = "_content_type"
+
+	ContentTypeJSON = "application/json"
+	ContentTypeForm = "application/x-www-form-urlencoded"
+	ContentTypeXML  = "application/xml"
+)
+
+type contentTypeKey struct{}
+
+// ContextWithContentType returns a context carrying the content type of the payload, triggers use
+// it to provide the content type of the InputPayloadKey input
+func ContextWithContentType(ctx context.Context, contentType string) context.Context {
+	return context.WithValue(ctx, contentTypeKey{}, contentType)
+}
+
+func contentTypeFromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	contentType, _ := ctx.Value(contentTypeKey{}).(string)
+	return contentType
+}
+
+// InputDecoder decodes a raw payload into flow inputs.  For example, a decoder for protobuf
+// messages can unmarshal the payload into the message type and convert it to a map using
+// protojson, and be registered with:
+//
+//	flow.RegisterInputDecoder("application/x-protobuf", flow.InputDecoderFunc(decodeOrder))
+type InputDecoder interface {
+	Decode(payload []byte) (map[string]interface{}, error)
+}
+
+// InputDecoderFunc is an adapter to allow the use of ordinary functions as an InputDecoder
+type InputDecoderFunc func(payload []byte) (map[string]interface{}, error)
+
+func (f InputDecoderFunc) Decode(payload []byte) (map[string]interface{}, error) {
+	return f(payload)
+}
+
+func decodeJSON(payload []byte) (map[string]interface{}, error) {
+	var inputs map[string]interface{}
+	err := json.Unmarshal(payload, &inputs)
+	return inputs, err
+}
+
+// decodeForm decodes form fields, a field with multiple values is decoded to a list of the values
+func decodeForm(payload []byte) (map[string]interface{}, error) {
+	values, err := url.ParseQuery(string(payload))
+	if err != nil {
+		return nil, err
+	}
+
+	inputs := make(map[string]interface{}, len(values))
+	for name, vals := range values {
+		if len(vals) == 1 {
+			inputs[name] = vals[0]
+			continue
+		}
+		list := make([]interface{}, len(vals))
+		for i, val := range vals {
+			list[i] = val
+		}
+		inputs[name] = list
+	}
+	return inputs, nil
+}
+
+// decodeXML decodes the children of the document's root element.  An element with only text is
+// decoded to the text, otherwise to a map of its children, with its attributes prefixed by '@'
+// and its text, if any, in "#text".  Repeated elements are decoded to a list.
+func decodeXML(payload []byte) (map[string]interface{}, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(payload))
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			return nil, fmt.Errorf("no root element")
+		}
+		if err != nil {
+			return nil, err
+		}
+		if start, ok := token.(xml.StartElement); ok {
+			root, err := decodeXMLElement(decoder, start)
+			if err != nil {
+				return nil, err
+			}
+			if inputs, ok := root.(map[string]interface{}); ok {
+				return inputs, nil
+			}
+			return map[string]interface{}{}, nil
+		}
+	}
+}
+
+func decodeXMLElement(decoder *xml.Decoder, start xml.StartElement) (interface{}, error) {
+	children := make(map[string]interface{})
+	for _, attr := range start.Attr {
+		children["@"+attr.Name.Local] = attr.Value
+	}
+
+	var text strings.Builder
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return nil, err
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			child, err := decodeXMLElement(decoder, t)
+			if err != nil {
+				return nil, err
+			}
+			name := t.Name.Local
+			switch existing := children[name].(type) {
+			case nil:
+				children[name] = child
+			case []interface{}:
+				children[name] = append(existing, child)
+			default:
+				children[name] = []interface{}{existing, child}
+			}
+		case xml.CharData:
+			text.Write(t)
+		case xml.EndElement:
+			content := strings.TrimSpace(text.String())
+			if len(children) == 0 {
+				return content, nil
+			}
+			if content != "" {
+				children["#text"] = content
+			}
+			return children, nil
+		}
+	}
+}
+
+var (
+	decodersMu    sync.RWMutex // protects the input decoders
+	inputDecoders = map[string]InputDecoder{
+		ContentTypeJSON: InputDecoderFunc(decodeJSON),
+		ContentTypeForm: InputDecoderFunc(decodeForm),
+		ContentTypeXML:  InputDecoderFunc(decodeXML),
+		"text/xml":      InputDecoderFunc(decodeXML),
+	}
+)
+
+// RegisterInputDecoder registers the InputDecoder for a content type, replacing any existing one
+func RegisterInputDecoder(contentType string, decoder InputDecoder) {
+	decodersMu.Lock()
+	inputDecoders[normalizeContentType(contentType)] = decoder
+	decodersMu.Unlock()
+}
+
+// normalizeContentType removes the parameters (ex. charset) of a content type
+func normalizeContentType(contentType string) string {
+	if i := strings.Index(contentType, ";"); i >= 0 {
+		contentType = contentType[:i]
+	}
+	return strings.ToLower(strings.TrimSpace(contentType))
+}
+
+// decodeInputs decodes the raw payload in the inputs, if any, the decoded values are added to the
+// inputs unless an input with the same name was provided
+func decodeInputs(ctx context.Context, inputs map[string]interface{}) (map[string]interface{}, error) {
+	payload, exists := inputs[InputPayloadKey]
+	if !exists {
+		return inputs, nil
+	}
+
+	contentType, _ := inputs[InputContentTypeKey].(string)
+	if contentType == "" {
+		contentType = contentTypeFromContext(ctx)
+	}
+	if contentType == "" {
+		contentType = ContentTypeJSON
+	}
+
+	var raw []byte
+	switch t := payload.(type) {
+	case []byte:
+		raw = t
+	case string:
+		raw = []byte(t)
+	default:
+		return nil, fmt.Errorf("unsupported payload type %T, expected []byte or string", payload)
+	}
+
+	decodersMu.RLock()
+	decoder := inputDecoders[normalizeContentType(contentType)]
+	decodersMu.RUnlock()
+
+	if decoder == nil {
+		return nil, fmt.Errorf("no input decoder registered for content type [%s]", contentType)
+	}
+
+	decoded, err := decoder.Decode(raw)
+	if err != nil {
+		return nil, fmt.Errorf("unable to decode [%s] payload: %s", contentType, err.Error())
+	}
+
+	result := make(map[string]interface{}, len(inputs)+len(decoded))
+	for name, value := range decoded {
+		result[name] = value
+	}
+	for name, value := range inputs {
+		if name != InputPayloadKey && name != InputContentTypeKey {
+			result[name] = value
+		}
+	}
+
+	return result, nil
+}
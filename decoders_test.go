@@ -0,0 +1,46 @@
+package flow
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDecodeInputs(t *testing.T) {
+	inputs, err := decodeInputs(context.Background(), map[string]interface{}{InputPayloadKey: []byte(`{"name":"rex","age":3}`), "age": 4})
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]interface{}{"name": "rex", "age": 4}, inputs)
+
+	RegisterInputDecoder("text/csv", InputDecoderFunc(func(payload []byte) (map[string]interface{}, error) {
+		fields := strings.Split(string(payload), ",")
+		return map[string]interface{}{"name": fields[0], "type": fields[1]}, nil
+	}))
+	inputs, err = decodeInputs(context.Background(), map[string]interface{}{InputPayloadKey: "rex,dog", InputContentTypeKey: "text/csv; charset=utf-8"})
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]interface{}{"name": "rex", "type": "dog"}, inputs)
+
+	_, err = decodeInputs(context.Background(), map[string]interface{}{InputPayloadKey: "", InputContentTypeKey: "application/unknown"})
+	assert.NotNil(t, err)
+}
+
+func TestDecodeInputsContentTypes(t *testing.T) {
+	inputs, err := decodeInputs(context.Background(), map[string]interface{}{InputPayloadKey: "name=rex&tag=a&tag=b", InputContentTypeKey: ContentTypeForm})
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]interface{}{"name": "rex", "tag": []interface{}{"a", "b"}}, inputs)
+
+	ctx := ContextWithContentType(context.Background(), "text/xml; charset=utf-8")
+	payload := `<pet id="1"><name>rex</name><tag>a</tag><tag>b</tag><owner><name>ann</name></owner></pet>`
+	inputs, err = decodeInputs(ctx, map[string]interface{}{InputPayloadKey: payload})
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"@id":   "1",
+		"name":  "rex",
+		"tag":   []interface{}{"a", "b"},
+		"owner": map[string]interface{}{"name": "ann"},
+	}, inputs)
+
+	_, err = decodeInputs(ctx, map[string]interface{}{InputPayloadKey: "<pet>"})
+	assert.NotNil(t, err)
+}
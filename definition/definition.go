@@ -30,6 +30,10 @@ type Definition struct {
 	metadata *metadata.IOMetadata
 
 	errorHandler *ErrorHandler
+
+	annotations map[string]string
+
+	hash string
 }
 
 // Name returns the name of the definition
@@ -59,6 +63,20 @@ func (d *Definition) GetLink(linkID int) *Link {
 	return task
 }
 
+// Annotations returns a copy of the custom metadata of the flow (ex. owner, SLA or tags)
+func (d *Definition) Annotations() map[string]string {
+	annotations := make(map[string]string, len(d.annotations))
+	for name, value := range d.annotations {
+		annotations[name] = value
+	}
+	return annotations
+}
+
+// Hash returns the hash of the content of the definition, empty if it couldn't be computed
+func (d *Definition) Hash() string {
+	return d.hash
+}
+
 func (d *Definition) ExplicitReply() bool {
 	return d.explicitReply
 }
@@ -293,6 +311,11 @@ type Task struct {
 
 	loopCfg          *LoopConfig
 	retryOnErrConfig RetryOnError
+	skipIf           expression.Expr
+	featureFlag      string
+	cacheOutputs     bool
+
+	rep string
 
 	toLinks   []*Link
 	fromLinks []*Link
@@ -326,6 +349,22 @@ func (task *Task) RetryOnErrConfig() RetryOnError {
 	return task.retryOnErrConfig
 }
 
+// SkipIf returns the expression that determines if the task is skipped, nil if there is none
+func (task *Task) SkipIf() expression.Expr {
+	return task.skipIf
+}
+
+// FeatureFlag returns the name of the feature flag gating the task, empty if there is none
+func (task *Task) FeatureFlag() string {
+	return task.featureFlag
+}
+
+// CacheOutputs indicates whether the outputs of the task are reused when it is executed again by
+// the same instance with identical inputs, ex. in a loop (see the task's cacheOutputs setting)
+func (task *Task) CacheOutputs() bool {
+	return task.cacheOutputs
+}
+
 func (task *Task) LoopConfig() *LoopConfig {
 	return task.loopCfg
 }
@@ -344,6 +383,14 @@ func (task *Task) String() string {
 	return fmt.Sprintf("Task[%s] '%s'", task.id, task.name)
 }
 
+// Equal returns true if the task was created from the same definition as the other task
+func (task *Task) Equal(other *Task) bool {
+	if task == nil || other == nil {
+		return task == other
+	}
+	return task.id == other.id && task.rep == other.rep
+}
+
 // IsScope returns flag indicating if the Task is a scope task (a container of attributes)
 func (task *Task) IsScope() bool {
 	return task.isScope
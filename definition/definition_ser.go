@@ -1,6 +1,9 @@
 package definition
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"strconv"
@@ -28,6 +31,9 @@ type DefinitionRep struct {
 	Tasks         []*TaskRep           `json:"tasks"`
 	Links         []*LinkRep           `json:"links,omitempty"`
 	ErrorHandler  *ErrorHandlerRep     `json:"errorHandler,omitempty"`
+	// Annotations are custom metadata about the flow (ex. owner, SLA or tags), they aren't used by
+	// the engine
+	Annotations map[string]string `json:"annotations,omitempty"`
 }
 
 // ErrorHandlerRep is a serializable representation of the error flow
@@ -43,6 +49,11 @@ type TaskRep struct {
 	Name           string                 `json:"name,omitempty"`
 	Settings       map[string]interface{} `json:"settings,omitempty"`
 	ActivityCfgRep *activity.Config       `json:"activity"`
+	// SkipIf is an expression evaluated before the task's activity, the task is skipped when it's true
+	SkipIf string `json:"skipIf,omitempty"`
+	// FeatureFlag is the name of the feature flag gating the task, the task is skipped unless the
+	// flag is on
+	FeatureFlag string `json:"featureFlag,omitempty"`
 }
 
 // LinkRep is a serializable representation of a flow LinkOld
@@ -55,6 +66,17 @@ type LinkRep struct {
 	Value  string `json:"value,omitempty"`
 }
 
+// hashRep returns the hash of the JSON encoding of the representation, empty if it can't be encoded
+func hashRep(rep *DefinitionRep) string {
+	data, err := json.Marshal(rep)
+	if err != nil {
+		return ""
+	}
+
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
 // NewDefinition creates a flow Definition from a serializable
 // definition representation
 func NewDefinition(rep *DefinitionRep) (def *Definition, err error) {
@@ -64,10 +86,12 @@ func NewDefinition(rep *DefinitionRep) (def *Definition, err error) {
 	ef := expression.NewFactory(GetDataResolver())
 
 	def = &Definition{}
+	def.hash = hashRep(rep)
 	def.name = rep.Name
 	def.modelID = rep.ModelID
 	def.metadata = rep.Metadata
 	def.explicitReply = rep.ExplicitReply
+	def.annotations = rep.Annotations
 	def.tasks = make(map[string]*Task)
 	def.links = make(map[int]*Link)
 
@@ -167,11 +191,26 @@ func createTask(def *Definition, rep *TaskRep, ef expression.Factory) (*Task, er
 		return nil, err
 	}
 
+	if cacheOutputs, ok := rep.Settings["cacheOutputs"]; ok {
+		task.cacheOutputs, err = coerce.ToBool(cacheOutputs)
+		if err != nil {
+			return nil, fmt.Errorf("invalid cacheOutputs setting for task '%s': %s", task.id, err.Error())
+		}
+	}
+
 	task.settingsMapper, err = mf.NewMapper(rep.Settings)
 	if err != nil {
 		return nil, err
 	}
 
+	if rep.SkipIf != "" {
+		task.skipIf, err = ef.NewExpr(rep.SkipIf)
+		if err != nil {
+			return nil, fmt.Errorf("invalid skipIf expression for task '%s': %s", task.id, err.Error())
+		}
+	}
+	task.featureFlag = rep.FeatureFlag
+
 	if rep.ActivityCfgRep != nil {
 
 		actCfg, err := createActivityConfig(task, rep.ActivityCfgRep, ef)
@@ -187,6 +226,11 @@ func createTask(def *Definition, rep *TaskRep, ef expression.Factory) (*Task, er
 		task.activityCfg = actCfg
 	}
 
+	// the serialized representation identifies the task's definition, see Task.Equal
+	if repJson, err := json.Marshal(rep); err == nil {
+		task.rep = string(repJson)
+	}
+
 	return task, nil
 }
 
@@ -281,8 +281,12 @@ func validate(t *testing.T, defJson string) {
 
 	retryOnErrCfg := task.RetryOnErrConfig()
 	assert.NotNil(t, retryOnErrCfg)
-	assert.Equal(t, 1, retryOnErrCfg.Count())
-	assert.Equal(t, 100, retryOnErrCfg.Interval())
+	count, err := retryOnErrCfg.Count(nil)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, count)
+	interval, err := retryOnErrCfg.Interval(nil)
+	assert.Nil(t, err)
+	assert.Equal(t, 100, interval)
 
 	ac := task.ActivityConfig()
 	assert.NotNil(t, ac)
@@ -313,8 +317,12 @@ func validate(t *testing.T, defJson string) {
 
 	retryOnErrCfg = task.RetryOnErrConfig()
 	assert.NotNil(t, retryOnErrCfg)
-	assert.Equal(t, 2, retryOnErrCfg.Count())
-	assert.Equal(t, 500, retryOnErrCfg.Interval())
+	count, err = retryOnErrCfg.Count(nil)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, count)
+	interval, err = retryOnErrCfg.Interval(nil)
+	assert.Nil(t, err)
+	assert.Equal(t, 500, interval)
 
 	ac = task.ActivityConfig()
 	assert.NotNil(t, ac)
@@ -458,3 +466,20 @@ func (a *LogActivity) Eval(context activity.Context) (done bool, err error) {
 	fmt.Println("Message :", message)
 	return true, nil
 }
+
+func TestDefinitionAnnotations(t *testing.T) {
+	defRep := &DefinitionRep{}
+	err := json.Unmarshal([]byte(`{"name":"Annotated","tasks":[],"annotations":{"owner":"payments","sla":"5s"}}`), defRep)
+	assert.Nil(t, err)
+
+	def, err := NewDefinition(defRep)
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]string{"owner": "payments", "sla": "5s"}, def.Annotations())
+
+	def.Annotations()["owner"] = "changed"
+	assert.Equal(t, "payments", def.Annotations()["owner"])
+
+	def, err = NewDefinition(&DefinitionRep{Name: "Plain"})
+	assert.Nil(t, err)
+	assert.Empty(t, def.Annotations())
+}
@@ -0,0 +1,194 @@
+package definition
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+
+	"github.com/project-flogo/core/data"
+)
+
+// ChangeType is the type of a change between two versions of a flow definition
+type ChangeType string
+
+const (
+	ChangeAdded   ChangeType = "added"
+	ChangeRemoved ChangeType = "removed"
+	ChangeChanged ChangeType = "changed"
+)
+
+// FieldDiff describes a change to a field, ex. a task's input mapping or a flow input
+type FieldDiff struct {
+	Name   string      `json:"name"`
+	Change ChangeType  `json:"change"`
+	From   interface{} `json:"from,omitempty"`
+	To     interface{} `json:"to,omitempty"`
+}
+
+// TaskDiff describes the changes to a task, fields are named by their path in the task's
+// definition, ex. 'activity.input.message'
+type TaskDiff struct {
+	ID     string       `json:"id"`
+	Fields []*FieldDiff `json:"fields"`
+}
+
+// DiffReport describes the differences between two versions of a flow definition
+type DiffReport struct {
+	AddedTasks   []string     `json:"addedTasks,omitempty"`
+	RemovedTasks []string     `json:"removedTasks,omitempty"`
+	ChangedTasks []*TaskDiff  `json:"changedTasks,omitempty"`
+	AddedLinks   []int        `json:"addedLinks,omitempty"`
+	RemovedLinks []int        `json:"removedLinks,omitempty"`
+	ChangedLinks []int        `json:"changedLinks,omitempty"`
+	Inputs       []*FieldDiff `json:"inputs,omitempty"`
+	Outputs      []*FieldDiff `json:"outputs,omitempty"`
+}
+
+// Empty returns true if the definitions are the same
+func (r *DiffReport) Empty() bool {
+	return len(r.AddedTasks) == 0 && len(r.RemovedTasks) == 0 && len(r.ChangedTasks) == 0 &&
+		len(r.AddedLinks) == 0 && len(r.RemovedLinks) == 0 && len(r.ChangedLinks) == 0 &&
+		len(r.Inputs) == 0 && len(r.Outputs) == 0
+}
+
+// Diff compares two versions of a flow definition, including their error handlers
+func Diff(a, b *Definition) *DiffReport {
+	report := &DiffReport{}
+
+	aTasks, bTasks := allTasks(a), allTasks(b)
+	for _, id := range sortedKeys(aTasks) {
+		bTask, exists := bTasks[id]
+		if !exists {
+			report.RemovedTasks = append(report.RemovedTasks, id)
+		} else if !aTasks[id].Equal(bTask) {
+			report.ChangedTasks = append(report.ChangedTasks, &TaskDiff{ID: id, Fields: diffFields(flattenRep(aTasks[id].rep), flattenRep(bTask.rep))})
+		}
+	}
+	for _, id := range sortedKeys(bTasks) {
+		if _, exists := aTasks[id]; !exists {
+			report.AddedTasks = append(report.AddedTasks, id)
+		}
+	}
+
+	aLinks, bLinks := allLinks(a), allLinks(b)
+	for id, aLink := range aLinks {
+		bLink, exists := bLinks[id]
+		if !exists {
+			report.RemovedLinks = append(report.RemovedLinks, id)
+		} else if !linksEqual(aLink, bLink) {
+			report.ChangedLinks = append(report.ChangedLinks, id)
+		}
+	}
+	for id := range bLinks {
+		if _, exists := aLinks[id]; !exists {
+			report.AddedLinks = append(report.AddedLinks, id)
+		}
+	}
+	sort.Ints(report.RemovedLinks)
+	sort.Ints(report.ChangedLinks)
+	sort.Ints(report.AddedLinks)
+
+	var aInputs, aOutputs, bInputs, bOutputs map[string]data.TypedValue
+	if a.metadata != nil {
+		aInputs, aOutputs = a.metadata.Input, a.metadata.Output
+	}
+	if b.metadata != nil {
+		bInputs, bOutputs = b.metadata.Input, b.metadata.Output
+	}
+	report.Inputs = diffFields(flattenMetadata(aInputs), flattenMetadata(bInputs))
+	report.Outputs = diffFields(flattenMetadata(aOutputs), flattenMetadata(bOutputs))
+
+	return report
+}
+
+func allTasks(def *Definition) map[string]*Task {
+	tasks := make(map[string]*Task, len(def.tasks))
+	for id, task := range def.tasks {
+		tasks[id] = task
+	}
+	if def.errorHandler != nil {
+		for id, task := range def.errorHandler.tasks {
+			tasks[id] = task
+		}
+	}
+	return tasks
+}
+
+func allLinks(def *Definition) map[int]*Link {
+	links := make(map[int]*Link, len(def.links))
+	for id, link := range def.links {
+		links[id] = link
+	}
+	if def.errorHandler != nil {
+		for id, link := range def.errorHandler.links {
+			links[id] = link
+		}
+	}
+	return links
+}
+
+func linksEqual(a, b *Link) bool {
+	return a.fromTask.id == b.fromTask.id && a.toTask.id == b.toTask.id && a.linkType == b.linkType &&
+		a.value == b.value && a.label == b.label
+}
+
+// flattenRep flattens a task's serialized representation into its fields, keyed by path
+func flattenRep(rep string) map[string]interface{} {
+	var value map[string]interface{}
+	_ = json.Unmarshal([]byte(rep), &value)
+
+	fields := make(map[string]interface{})
+	flatten("", value, fields)
+	delete(fields, "id")
+	return fields
+}
+
+func flatten(prefix string, value map[string]interface{}, fields map[string]interface{}) {
+	for name, v := range value {
+		if obj, ok := v.(map[string]interface{}); ok {
+			flatten(prefix+name+".", obj, fields)
+		} else {
+			fields[prefix+name] = v
+		}
+	}
+}
+
+func flattenMetadata(attrs map[string]data.TypedValue) map[string]interface{} {
+	fields := make(map[string]interface{}, len(attrs))
+	for name, tv := range attrs {
+		if tv == nil {
+			fields[name] = nil
+		} else {
+			fields[name] = map[string]interface{}{"type": tv.Type().String(), "value": tv.Value()}
+		}
+	}
+	return fields
+}
+
+func diffFields(a, b map[string]interface{}) []*FieldDiff {
+	var diffs []*FieldDiff
+	for _, name := range sortedKeys(a) {
+		bValue, exists := b[name]
+		if !exists {
+			diffs = append(diffs, &FieldDiff{Name: name, Change: ChangeRemoved, From: a[name]})
+		} else if !reflect.DeepEqual(a[name], bValue) {
+			diffs = append(diffs, &FieldDiff{Name: name, Change: ChangeChanged, From: a[name], To: bValue})
+		}
+	}
+	for _, name := range sortedKeys(b) {
+		if _, exists := a[name]; !exists {
+			diffs = append(diffs, &FieldDiff{Name: name, Change: ChangeAdded, To: b[name]})
+		}
+	}
+	return diffs
+}
+
+func sortedKeys(m interface{}) []string {
+	keys := reflect.ValueOf(m).MapKeys()
+	names := make([]string, len(keys))
+	for i, key := range keys {
+		names[i] = key.String()
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,53 @@
+package definition
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const diffDefJSON = `
+{
+  "name": "Diff Flow",
+  "model": "test",
+  "metadata": {
+    "input": [ { "name": "name", "type": "string" } ]
+  },
+  "tasks": [
+    { "id": "log1", "activity": { "ref": "log", "input": { "message": "=$.name" } } },
+    { "id": "log2", "activity": { "ref": "log", "input": { "message": "done" } } }
+  ],
+  "links": [ { "from": "log1", "to": "log2" } ]
+}
+`
+
+func newDiffDef(t *testing.T, replacer *strings.Replacer) *Definition {
+	defRep := &DefinitionRep{}
+	err := json.Unmarshal([]byte(replacer.Replace(diffDefJSON)), defRep)
+	assert.Nil(t, err)
+	def, err := NewDefinition(defRep)
+	assert.Nil(t, err)
+	return def
+}
+
+func TestDiff(t *testing.T) {
+	a := newDiffDef(t, strings.NewReplacer())
+	assert.True(t, Diff(a, newDiffDef(t, strings.NewReplacer())).Empty())
+
+	b := newDiffDef(t, strings.NewReplacer(`"done"`, `"finished"`, `"type": "string"`, `"type": "int"`, `"log2"`, `"log3"`))
+	report := Diff(a, b)
+	assert.Equal(t, []string{"log2"}, report.RemovedTasks)
+	assert.Equal(t, []string{"log3"}, report.AddedTasks)
+	assert.Empty(t, report.ChangedTasks)
+	assert.Equal(t, []int{0}, report.ChangedLinks)
+	assert.Len(t, report.Inputs, 1)
+	assert.Equal(t, ChangeChanged, report.Inputs[0].Change)
+
+	b = newDiffDef(t, strings.NewReplacer(`"done"`, `"finished"`))
+	report = Diff(a, b)
+	assert.Len(t, report.ChangedTasks, 1)
+	assert.Equal(t, "log2", report.ChangedTasks[0].ID)
+	assert.Equal(t, []*FieldDiff{{Name: "activity.input.message", Change: ChangeChanged, From: "done", To: "finished"}}, report.ChangedTasks[0].Fields)
+}
@@ -18,6 +18,7 @@ var defResolver = resolve.NewCompositeResolver(map[string]resolve.Resolver{
 	"activity":  &ActivityResolver{},
 	"flowctx":   &FlowContextResolver{},
 	"error":     &ErrorResolver{},
+	"flags":     &FlagResolver{},
 	"flow":      &FlowResolver{}})
 
 func GetDataResolver() resolve.CompositeResolver {
@@ -42,6 +43,23 @@ func (r *FlowResolver) Resolve(scope data.Scope, itemName, valueName string) (in
 	return value, nil
 }
 
+// FlagResolver resolves feature flags using the syntax: $flags.name
+type FlagResolver struct {
+}
+
+func (r *FlagResolver) GetResolverInfo() *resolve.ResolverInfo {
+	return resolverInfo
+}
+
+func (r *FlagResolver) Resolve(scope data.Scope, itemName, valueName string) (interface{}, error) {
+
+	value, exists := scope.GetValue("_flags." + valueName)
+	if !exists {
+		return nil, fmt.Errorf("failed to resolve feature flag: '%s'", valueName)
+	}
+	return value, nil
+}
+
 var dynamicItemResolver = resolve.NewResolverInfo(false, true)
 
 type ActivityResolver struct {
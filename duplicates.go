@@ -0,0 +1,62 @@
+package flow
+
+import (
+	"fmt"
+)
+
+// DuplicateIDPolicy determines what happens when a flow is started with the preserved instance ID
+// of an instance that is still running
+type DuplicateIDPolicy string
+
+const (
+	// DuplicateIDIgnore starts the new instance alongside the running one, this is the default
+	DuplicateIDIgnore DuplicateIDPolicy = "ignore"
+	// DuplicateIDReject fails the start with a DuplicateInstanceError
+	DuplicateIDReject DuplicateIDPolicy = "reject"
+	// DuplicateIDReplace cancels the running instance and starts the new one
+	DuplicateIDReplace DuplicateIDPolicy = "replace"
+)
+
+var duplicateIDPolicy = DuplicateIDIgnore
+
+// ToDuplicateIDPolicy converts a setting value to a DuplicateIDPolicy
+func ToDuplicateIDPolicy(policy string) (DuplicateIDPolicy, error) {
+	switch DuplicateIDPolicy(policy) {
+	case "", DuplicateIDIgnore:
+		return DuplicateIDIgnore, nil
+	case DuplicateIDReject, DuplicateIDReplace:
+		return DuplicateIDPolicy(policy), nil
+	default:
+		return DuplicateIDIgnore, fmt.Errorf("unsupported duplicate instance id policy [%s]", policy)
+	}
+}
+
+// DuplicateInstanceError is returned when a flow is started with the preserved instance ID of a
+// running instance and the policy is DuplicateIDReject
+type DuplicateInstanceError struct {
+	ID string
+}
+
+func (e *DuplicateInstanceError) Error() string {
+	return fmt.Sprintf("flow instance [%s] is already running", e.ID)
+}
+
+// checkDuplicateInstance applies the policy when an instance with the specified ID is running, the
+// caller must hold riMu so that the instance is registered in the same step
+func checkDuplicateInstance(id string, policy DuplicateIDPolicy) error {
+	ri := runningInstances[id]
+	if ri == nil {
+		return nil
+	}
+
+	switch policy {
+	case DuplicateIDReject:
+		return &DuplicateInstanceError{ID: id}
+	case DuplicateIDReplace:
+		logger.Warnf("Cancelling running Flow Instance [%s], it is replaced by a new instance with the same id", id)
+		ri.cancel()
+	default:
+		logger.Warnf("Starting Flow Instance [%s] while an instance with the same id is running", id)
+	}
+	return nil
+}
@@ -0,0 +1,53 @@
+package flow
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/project-flogo/core/support/log"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckDuplicateInstance(t *testing.T) {
+	_, err := ToDuplicateIDPolicy("unknown")
+	assert.NotNil(t, err)
+
+	ri, err := registerInstance(newTestInstance(t, "dup"), "res://flow:dup", DuplicateIDReject)
+	assert.Nil(t, err)
+	defer discardInstance(ri)
+
+	_, err = registerInstance(newTestInstance(t, "dup"), "res://flow:dup", DuplicateIDReject)
+	assert.IsType(t, &DuplicateInstanceError{}, err)
+
+	logger = log.ChildLogger(log.RootLogger(), "flow")
+	replacing, err := registerInstance(newTestInstance(t, "dup"), "res://flow:dup", DuplicateIDReplace)
+	assert.Nil(t, err)
+	defer discardInstance(replacing)
+	assert.True(t, ri.isCancelled())
+	assert.Equal(t, replacing, getRunningInstance("dup"))
+}
+
+func TestRegisterDuplicateInstanceOnce(t *testing.T) {
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	var registered []*runningInstance
+	for i := 0; i < 10; i++ {
+		inst := newTestInstance(t, "dup")
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if ri, err := registerInstance(inst, "res://flow:dup", DuplicateIDReject); err == nil {
+				mu.Lock()
+				registered = append(registered, ri)
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	assert.Len(t, registered, 1)
+	for _, ri := range registered {
+		discardInstance(ri)
+	}
+	assert.Nil(t, getRunningInstance("dup"))
+}
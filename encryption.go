@@ -0,0 +1,16 @@
+package flow
+
+import (
+	"github.com/project-flogo/flow/state"
+)
+
+var attributeEncryptor state.AttributeEncryptor
+var encryptedAttrs []string
+
+// SetAttributeEncryption sets the AttributeEncryptor used to encrypt the values of the attributes
+// at the specified paths (see state.NewEncryptingRecorder) when the state of instances is recorded.
+// It must be set before the engine is started, nil records the attributes as is.
+func SetAttributeEncryption(encryptor state.AttributeEncryptor, paths []string) {
+	attributeEncryptor = encryptor
+	encryptedAttrs = paths
+}
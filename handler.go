@@ -0,0 +1,134 @@
+package flow
+
+import (
+	"errors"
+	"time"
+
+	"github.com/project-flogo/core/action"
+	"github.com/project-flogo/core/support/log"
+	"github.com/project-flogo/flow/instance"
+)
+
+// ErrResultDropped is delivered, with a failed flow status, to a result handler that didn't accept
+// a result within the result timeout (see ExecOptions.ResultTimeout)
+var ErrResultDropped = errors.New("result handler did not accept a result in time, it was dropped")
+
+type result struct {
+	data map[string]interface{}
+	err  error
+}
+
+// bufferedResultHandler decouples the instance from a slow action.ResultHandler, results are
+// buffered and delivered on a separate goroutine.  If the buffer stays full for longer than the
+// timeout, the result is dropped instead of stalling the instance, and the handler is delivered
+// ErrResultDropped after the buffered results.
+type bufferedResultHandler struct {
+	handler action.ResultHandler
+	timeout time.Duration
+	logger  log.Logger
+	results chan *result
+	// dropped is the number of dropped results, it is read by deliver once results is closed
+	dropped int
+}
+
+func newBufferedResultHandler(handler action.ResultHandler, bufferSize int, timeout time.Duration, logger log.Logger) action.ResultHandler {
+	if bufferSize <= 0 {
+		bufferSize = 1
+	}
+
+	h := &bufferedResultHandler{
+		handler: handler,
+		timeout: timeout,
+		logger:  logger,
+		results: make(chan *result, bufferSize),
+	}
+
+	go h.deliver()
+
+	return h
+}
+
+func (h *bufferedResultHandler) HandleResult(resultData map[string]interface{}, err error) {
+	r := &result{data: resultData, err: err}
+
+	if h.timeout <= 0 {
+		h.results <- r
+		return
+	}
+
+	timer := time.NewTimer(h.timeout)
+	defer timer.Stop()
+
+	select {
+	case h.results <- r:
+	case <-timer.C:
+		h.logger.Errorf("Result handler did not accept result within %s, result dropped", h.timeout)
+		h.dropped++
+	}
+}
+
+// Done signals that no more results will be delivered, the wrapped handler's Done is
+// invoked once all buffered results have been delivered
+func (h *bufferedResultHandler) Done() {
+	close(h.results)
+}
+
+func (h *bufferedResultHandler) deliver() {
+	for r := range h.results {
+		h.handler.HandleResult(r.data, r.err)
+	}
+	if h.dropped > 0 {
+		h.handler.HandleResult(withFlowStatus(nil, FlowStatusFailed), ErrResultDropped)
+	}
+	h.handler.Done()
+}
+
+// envelopeResultHandler wraps the results in an envelope with the execution metadata of the
+// instance, the flow status is moved from the results to the metadata.  The instance isn't read
+// when the results are handled, a suspended instance may have been resumed by then.
+type envelopeResultHandler struct {
+	handler    action.ResultHandler
+	instanceID string
+	startTime  time.Time
+	steps      func() int
+}
+
+func newEnvelopeResultHandler(handler action.ResultHandler, inst *instance.IndependentInstance, steps func() int) action.ResultHandler {
+	return &envelopeResultHandler{handler: handler, instanceID: inst.ID(), startTime: inst.StartTime(), steps: steps}
+}
+
+func (h *envelopeResultHandler) HandleResult(resultData map[string]interface{}, err error) {
+	data := make(map[string]interface{}, len(resultData))
+	for name, value := range resultData {
+		data[name] = value
+	}
+
+	meta := map[string]interface{}{
+		"instanceId": h.instanceID,
+		"durationMs": time.Since(h.startTime).Nanoseconds() / int64(time.Millisecond),
+		"steps":      h.steps(),
+	}
+	if status, ok := data[FlowStatusKey]; ok {
+		meta["status"] = status
+		delete(data, FlowStatusKey)
+	}
+
+	h.handler.HandleResult(map[string]interface{}{"data": data, "meta": meta}, err)
+}
+
+func (h *envelopeResultHandler) Done() {
+	h.handler.Done()
+}
+
+// discardResultHandler discards the results of a fire and forget instance (see
+// ExecOptions.FireAndForget), the wrapped handler is only notified when the instance is done
+type discardResultHandler struct {
+	handler action.ResultHandler
+}
+
+func (h *discardResultHandler) HandleResult(resultData map[string]interface{}, err error) {
+}
+
+func (h *discardResultHandler) Done() {
+	h.handler.Done()
+}
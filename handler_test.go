@@ -0,0 +1,64 @@
+package flow
+
+import (
+	"testing"
+	"time"
+
+	"github.com/project-flogo/core/support/log"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEnvelopeResultHandler(t *testing.T) {
+	h := &testResultHandler{done: make(chan struct{})}
+	envelope := newEnvelopeResultHandler(h, newTestInstance(t, "envelope"), func() int { return 3 })
+	envelope.HandleResult(withFlowStatus(map[string]interface{}{"total": 10}, FlowStatusCompleted), nil)
+	envelope.Done()
+	<-h.done
+
+	data, _ := h.results["data"].(map[string]interface{})
+	assert.Equal(t, map[string]interface{}{"total": 10}, data)
+
+	meta, _ := h.results["meta"].(map[string]interface{})
+	assert.Equal(t, "envelope", meta["instanceId"])
+	assert.Equal(t, 3, meta["steps"])
+	assert.Equal(t, FlowStatusCompleted, meta["status"])
+	assert.Contains(t, meta, "durationMs")
+}
+
+func TestDiscardResultHandler(t *testing.T) {
+	h := &testResultHandler{done: make(chan struct{})}
+	discard := &discardResultHandler{handler: h}
+
+	discard.HandleResult(withFlowStatus(map[string]interface{}{"total": 10}, FlowStatusCompleted), nil)
+	discard.Done()
+	<-h.done
+
+	assert.Nil(t, h.results)
+}
+
+// blockingResultHandler doesn't accept results until it is released
+type blockingResultHandler struct {
+	testResultHandler
+	release chan struct{}
+}
+
+func (h *blockingResultHandler) HandleResult(results map[string]interface{}, err error) {
+	<-h.release
+	h.testResultHandler.HandleResult(results, err)
+}
+
+func TestBufferedResultHandlerDropped(t *testing.T) {
+	h := &blockingResultHandler{testResultHandler: testResultHandler{done: make(chan struct{})}, release: make(chan struct{})}
+	buffered := newBufferedResultHandler(h, 1, 10*time.Millisecond, log.RootLogger())
+
+	// the first result is being delivered, the second is buffered and the third is dropped
+	buffered.HandleResult(map[string]interface{}{"n": 1}, nil)
+	buffered.HandleResult(map[string]interface{}{"n": 2}, nil)
+	buffered.HandleResult(map[string]interface{}{"n": 3}, nil)
+	buffered.Done()
+	close(h.release)
+	<-h.done
+
+	assert.Equal(t, ErrResultDropped, h.err)
+	assert.Equal(t, FlowStatusFailed, h.results[FlowStatusKey])
+}
@@ -0,0 +1,49 @@
+package flow
+
+import (
+	"github.com/project-flogo/flow/state"
+)
+
+// HealthStatus reports the health of the flow subsystem
+type HealthStatus struct {
+	// Initialized indicates that the flow action factory has been initialized
+	Initialized bool `json:"initialized"`
+	// RecorderConfigured indicates that a state recorder service is configured
+	RecorderConfigured bool `json:"recorderConfigured"`
+	// RecorderReachable indicates that the state recorder service responded to a ping
+	RecorderReachable bool `json:"recorderReachable"`
+	// RecorderError is the error returned when pinging the state recorder service
+	RecorderError string `json:"recorderError,omitempty"`
+	// RecordingMode is the configured state recording mode
+	RecordingMode state.RecordingMode `json:"recordingMode"`
+	// ActiveInstances is the number of flow instances currently being executed
+	ActiveInstances int `json:"activeInstances"`
+}
+
+// Ready checks if the flow subsystem is ready to run flows
+func (h *HealthStatus) Ready() bool {
+	return h.Initialized && (!h.RecorderConfigured || h.RecorderReachable)
+}
+
+// Health reports the health of the flow subsystem, it is suitable for use in readiness probes
+func Health() *HealthStatus {
+	status := &HealthStatus{
+		Initialized:   flowManager != nil,
+		RecordingMode: stateRecordingMode,
+	}
+
+	if stateRecorder != nil {
+		status.RecorderConfigured = true
+		if err := state.Ping(stateRecorder); err != nil {
+			status.RecorderError = err.Error()
+		} else {
+			status.RecorderReachable = true
+		}
+	}
+
+	riMu.RLock()
+	status.ActiveInstances = len(runningInstances)
+	riMu.RUnlock()
+
+	return status
+}
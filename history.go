@@ -0,0 +1,39 @@
+package flow
+
+import (
+	"fmt"
+
+	"github.com/project-flogo/flow/state"
+)
+
+// GetInstanceHistory returns the recorded steps of the instance with the specified ID, ordered by
+// step ID.  The state recorder service must implement state.HistoryReader, state.ErrInstanceNotFound
+// is returned if the instance was never recorded.
+func GetInstanceHistory(id string) ([]*state.Step, error) {
+	if stateRecorder == nil {
+		return nil, fmt.Errorf("unable to get history of instance [%s], state recording is not enabled", id)
+	}
+
+	return state.GetSteps(stateRecorder, id)
+}
+
+// GetInstanceSnapshot returns the latest recorded snapshot of the instance with the specified ID,
+// ex. to restart it.  The state recorder service must implement state.SnapshotReader.
+func GetInstanceSnapshot(id string) (*state.Snapshot, error) {
+	if stateRecorder == nil {
+		return nil, fmt.Errorf("unable to get snapshot of instance [%s], state recording is not enabled", id)
+	}
+
+	return state.GetSnapshot(stateRecorder, id)
+}
+
+// GetInstanceCheckpoint returns the latest snapshot of the instance with the specified ID recorded
+// for the named checkpoint (see instance.CheckpointContext).  The state recorder service must
+// implement state.CheckpointReader.
+func GetInstanceCheckpoint(id, name string) (*state.Snapshot, error) {
+	if stateRecorder == nil {
+		return nil, fmt.Errorf("unable to get checkpoint [%s] of instance [%s], state recording is not enabled", name, id)
+	}
+
+	return state.GetCheckpoint(stateRecorder, id, name)
+}
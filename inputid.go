@@ -0,0 +1,29 @@
+package flow
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+)
+
+// instanceIDFromInput derives a stable instance ID from the value of the specified input, so
+// starts of the flow with the same value get the same ID
+func instanceIDFromInput(flowURI, field string, inputs map[string]interface{}) (string, error) {
+	val, exists := inputs[field]
+	if !exists || val == nil {
+		return "", fmt.Errorf("unable to derive instance id for flow [%s], input '%s' not set", flowURI, field)
+	}
+
+	b, err := json.Marshal(val)
+	if err != nil {
+		return "", fmt.Errorf("unable to derive instance id for flow [%s] from input '%s': %s", flowURI, field, err.Error())
+	}
+
+	h := sha256.New()
+	h.Write([]byte(flowURI))
+	h.Write([]byte{0})
+	h.Write(b)
+
+	return hex.EncodeToString(h.Sum(nil)[:16]), nil
+}
@@ -0,0 +1,25 @@
+package flow
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInstanceIDFromInput(t *testing.T) {
+	id, err := instanceIDFromInput("res://flow:a", "orderId", map[string]interface{}{"orderId": "123", "qty": 1})
+	assert.Nil(t, err)
+	assert.Len(t, id, 32)
+
+	same, _ := instanceIDFromInput("res://flow:a", "orderId", map[string]interface{}{"orderId": "123", "qty": 2})
+	assert.Equal(t, id, same)
+
+	other, _ := instanceIDFromInput("res://flow:a", "orderId", map[string]interface{}{"orderId": "124"})
+	assert.NotEqual(t, id, other)
+
+	otherFlow, _ := instanceIDFromInput("res://flow:b", "orderId", map[string]interface{}{"orderId": "123"})
+	assert.NotEqual(t, id, otherFlow)
+
+	_, err = instanceIDFromInput("res://flow:a", "orderId", map[string]interface{}{})
+	assert.NotNil(t, err)
+}
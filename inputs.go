@@ -0,0 +1,139 @@
+package flow
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/project-flogo/core/data"
+	"github.com/project-flogo/core/data/mapper"
+	"github.com/project-flogo/core/data/metadata"
+	"github.com/project-flogo/flow/definition"
+	flowsupport "github.com/project-flogo/flow/support"
+)
+
+// newInputMapper creates a mapper for the configured input mappings, a mapping value is either
+// the name of a field in the incoming inputs or an expression prefixed with '='
+func newInputMapper(inputMappings map[string]string) (mapper.Mapper, error) {
+	if len(inputMappings) == 0 {
+		return nil, nil
+	}
+
+	mappings := make(map[string]interface{}, len(inputMappings))
+	for flowInput, src := range inputMappings {
+		if strings.HasPrefix(src, "=") {
+			mappings[flowInput] = src
+		} else {
+			mappings[flowInput] = "=$." + src
+		}
+	}
+
+	return definition.GetMapperFactory().NewMapper(mappings)
+}
+
+// applyInputMapper applies the input mapper to the incoming inputs, the mapped values
+// are added to (or replace) the incoming inputs
+func applyInputMapper(inputMapper mapper.Mapper, flowURI string, inputs map[string]interface{}) (map[string]interface{}, error) {
+	mapped, err := inputMapper.Apply(data.NewSimpleScope(inputs, nil))
+	if err != nil {
+		return nil, fmt.Errorf("unable to apply input mappings for flow [%s]: %s", flowURI, err.Error())
+	}
+
+	result := make(map[string]interface{}, len(inputs)+len(mapped))
+	for name, value := range inputs {
+		result[name] = value
+	}
+	for name, value := range mapped {
+		result[name] = value
+	}
+
+	return result, nil
+}
+
+// checkEmptyInputs rejects a start without inputs if the flow declares inputs that have no default
+// value, a flow whose declared inputs all have defaults can start without inputs
+func checkEmptyInputs(flowURI string, md *metadata.IOMetadata) error {
+	required := requiredInputs(md)
+	if len(required) == 0 {
+		return nil
+	}
+
+	return fmt.Errorf("flow [%s] started without inputs, inputs without a default value: %s", flowURI, strings.Join(required, ", "))
+}
+
+// requiredInputs returns the sorted names of the declared inputs that have no default value
+func requiredInputs(md *metadata.IOMetadata) []string {
+	if md == nil {
+		return nil
+	}
+
+	var required []string
+	for name, value := range md.Input {
+		if value == nil || value.Value() == nil {
+			required = append(required, name)
+		}
+	}
+
+	sort.Strings(required)
+	return required
+}
+
+// ValidationError describes a problem with the inputs of a flow, Input is empty when the problem
+// isn't specific to an input
+type ValidationError struct {
+	Input   string `json:"input,omitempty"`
+	Message string `json:"message"`
+}
+
+func (e ValidationError) Error() string {
+	if e.Input == "" {
+		return e.Message
+	}
+	return fmt.Sprintf("input '%s' %s", e.Input, e.Message)
+}
+
+// ValidateFlowInputs checks the inputs against the inputs declared by the flow without running it,
+// ex. to reject a request at the edge.  All the problems are returned, an input is invalid if it is
+// declared without a default value but missing, or if its value can't be coerced to the declared
+// type.  Inputs that aren't declared are ignored.
+func ValidateFlowInputs(flowURI string, inputs map[string]interface{}) []ValidationError {
+	flowURI = flowsupport.ResolveFlowURI(flowURI)
+	def, _, err := flowsupport.GetDefinition(flowURI)
+	if err != nil {
+		return []ValidationError{{Message: err.Error()}}
+	}
+	if def == nil {
+		return []ValidationError{{Message: fmt.Sprintf("flow not found for URI: %s", flowURI)}}
+	}
+
+	return validateInputs(def.Metadata(), inputs)
+}
+
+// validateInputs checks the inputs against the declared inputs, see ValidateFlowInputs
+func validateInputs(md *metadata.IOMetadata, inputs map[string]interface{}) []ValidationError {
+	if md == nil {
+		return nil
+	}
+
+	var problems []ValidationError
+	for _, name := range requiredInputs(md) {
+		if _, exists := inputs[name]; !exists {
+			problems = append(problems, ValidationError{Input: name, Message: "is missing"})
+		}
+	}
+
+	names := make([]string, 0, len(md.Input))
+	for name := range md.Input {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		val, exists := inputs[name]
+		if exists && !coercible(val, md.Input[name]) {
+			problems = append(problems, ValidationError{Input: name, Message: fmt.Sprintf("is not of type %s", md.Input[name].Type())})
+		}
+	}
+
+	return problems
+}
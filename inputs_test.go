@@ -0,0 +1,59 @@
+package flow
+
+import (
+	"testing"
+
+	"github.com/project-flogo/core/data"
+	"github.com/project-flogo/core/data/metadata"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestApplyInputMapper(t *testing.T) {
+	m, err := newInputMapper(nil)
+	assert.Nil(t, err)
+	assert.Nil(t, m)
+
+	m, err = newInputMapper(map[string]string{"orderId": "id", "customer": "=$.name"})
+	assert.Nil(t, err)
+	assert.NotNil(t, m)
+
+	inputs, err := applyInputMapper(m, "test", map[string]interface{}{"id": "1234", "name": "flogo"})
+	assert.Nil(t, err)
+	assert.Equal(t, "1234", inputs["orderId"])
+	assert.Equal(t, "flogo", inputs["customer"])
+	assert.Equal(t, "1234", inputs["id"])
+}
+
+func TestCheckEmptyInputs(t *testing.T) {
+	assert.Nil(t, checkEmptyInputs("test", nil))
+
+	md := &metadata.IOMetadata{Input: map[string]data.TypedValue{
+		"region":  data.NewTypedValue(data.TypeString, "us"),
+		"orderId": data.NewTypedValue(data.TypeString, nil),
+	}}
+	err := checkEmptyInputs("test", md)
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "orderId")
+	assert.NotContains(t, err.Error(), "region")
+
+	delete(md.Input, "orderId")
+	assert.Nil(t, checkEmptyInputs("test", md))
+}
+
+func TestValidateInputs(t *testing.T) {
+	md := &metadata.IOMetadata{Input: map[string]data.TypedValue{
+		"orderId":  data.NewTypedValue(data.TypeString, nil),
+		"quantity": data.NewTypedValue(data.TypeInt, nil),
+		"region":   data.NewTypedValue(data.TypeString, "us"),
+	}}
+
+	problems := validateInputs(md, map[string]interface{}{"quantity": "many", "other": 1})
+	assert.Equal(t, []ValidationError{
+		{Input: "orderId", Message: "is missing"},
+		{Input: "quantity", Message: "is not of type int"},
+	}, problems)
+	assert.Equal(t, "input 'orderId' is missing", problems[0].Error())
+
+	assert.Empty(t, validateInputs(md, map[string]interface{}{"orderId": "1", "quantity": "2"}))
+	assert.Empty(t, validateInputs(nil, nil))
+}
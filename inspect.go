@@ -0,0 +1,51 @@
+package flow
+
+import (
+	"fmt"
+	"sort"
+
+	flowsupport "github.com/project-flogo/flow/support"
+)
+
+// FlowInfo describes a flow definition
+type FlowInfo struct {
+	URI     string   `json:"uri"`
+	Name    string   `json:"name"`
+	Tasks   []string `json:"tasks"`
+	Inputs  []string `json:"inputs,omitempty"`
+	Outputs []string `json:"outputs,omitempty"`
+	// Annotations are the custom metadata of the flow (ex. owner, SLA or tags)
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+// InspectFlow describes the definition of the flow, ex. for tooling that routes alerts by the
+// owner annotated on the flow
+func InspectFlow(flowURI string) (*FlowInfo, error) {
+	flowURI = flowsupport.ResolveFlowURI(flowURI)
+	def, _, err := flowsupport.GetDefinition(flowURI)
+	if err != nil {
+		return nil, err
+	}
+	if def == nil {
+		return nil, fmt.Errorf("flow not found for URI: %s", flowURI)
+	}
+
+	info := &FlowInfo{URI: flowURI, Name: def.Name(), Annotations: def.Annotations()}
+	for _, task := range def.Tasks() {
+		info.Tasks = append(info.Tasks, task.ID())
+	}
+	sort.Strings(info.Tasks)
+
+	if md := def.Metadata(); md != nil {
+		for name := range md.Input {
+			info.Inputs = append(info.Inputs, name)
+		}
+		for name := range md.Output {
+			info.Outputs = append(info.Outputs, name)
+		}
+		sort.Strings(info.Inputs)
+		sort.Strings(info.Outputs)
+	}
+
+	return info, nil
+}
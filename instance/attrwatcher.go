@@ -0,0 +1,23 @@
+package instance
+
+// AttributeWatcher is notified of the attributes written by the tasks of an instance, ex. to
+// update a live monitoring UI.  It is called synchronously by the step writing the attribute, so
+// it should return quickly.
+type AttributeWatcher interface {
+	OnAttrChange(instanceID string, name string, value interface{})
+}
+
+// AttributeWatcherFunc is an adapter to allow the use of ordinary functions as an AttributeWatcher
+type AttributeWatcherFunc func(instanceID string, name string, value interface{})
+
+func (f AttributeWatcherFunc) OnAttrChange(instanceID string, name string, value interface{}) {
+	f(instanceID, name, value)
+}
+
+// notifyAttrChange notifies the instance's AttributeWatcher, if any, of an attribute written by
+// a step.  Attributes set when the instance is started aren't notified.
+func (inst *IndependentInstance) notifyAttrChange(name string, value interface{}) {
+	if inst.attrWatcher != nil && inst.stepID > 0 {
+		inst.attrWatcher.OnAttrChange(inst.id, name, value)
+	}
+}
@@ -0,0 +1,34 @@
+package instance
+
+// CheckpointContext is implemented by the activity.Context passed to activities executed by a flow,
+// it allows an activity to create a named checkpoint.  A snapshot of the instance, tagged with the
+// checkpoint's name, is recorded once the current step completes, regardless of the recording mode.
+// The snapshot is looked up by name to restart the instance from the checkpoint (see
+// state.CheckpointReader).
+type CheckpointContext interface {
+	Checkpoint(name string)
+}
+
+// Checkpoint implements CheckpointContext.Checkpoint
+func (ti *TaskInst) Checkpoint(name string) {
+	ti.logger.Debugf("Task[%s] - Creating checkpoint: %s", ti.taskID, name)
+	ti.flowInst.master.checkpoint = name
+}
+
+func (l *LegacyCtx) Checkpoint(name string) {
+	l.task.Checkpoint(name)
+}
+
+// recordCheckpoint records a snapshot of the instance for the checkpoint created during the
+// current step, if any
+func (inst *IndependentInstance) recordCheckpoint() error {
+	name := inst.checkpoint
+	if name == "" || inst.instRecorder == nil || inst.instRecorder.externalRecorder == nil {
+		return nil
+	}
+	inst.checkpoint = ""
+
+	snapshot := inst.Snapshot()
+	snapshot.Checkpoint = name
+	return inst.instRecorder.externalRecorder.RecordSnapshot(snapshot)
+}
@@ -0,0 +1,41 @@
+package instance
+
+import (
+	"testing"
+
+	"github.com/project-flogo/core/support/log"
+	"github.com/project-flogo/flow/state"
+	"github.com/stretchr/testify/assert"
+)
+
+type snapshotRecorder struct {
+	snapshots []*state.Snapshot
+}
+
+func (r *snapshotRecorder) RecordStart(state *state.FlowState) error { return nil }
+
+func (r *snapshotRecorder) RecordSnapshot(snapshot *state.Snapshot) error {
+	r.snapshots = append(r.snapshots, snapshot)
+	return nil
+}
+
+func (r *snapshotRecorder) RecordStep(step *state.Step) error { return nil }
+
+func (r *snapshotRecorder) RecordDone(state *state.FlowState) error { return nil }
+
+func TestRecordCheckpoint(t *testing.T) {
+	recorder := &snapshotRecorder{}
+	inst, err := NewIndependentInstance("test", "", getDef(), NewStateInstanceRecorder(recorder, state.RecordingModeOff, false), log.RootLogger())
+	assert.Nil(t, err)
+
+	assert.Nil(t, inst.recordCheckpoint())
+	assert.Empty(t, recorder.snapshots)
+
+	inst.checkpoint = "afterPayment"
+	assert.Nil(t, inst.recordCheckpoint())
+	assert.Len(t, recorder.snapshots, 1)
+	assert.Equal(t, "afterPayment", recorder.snapshots[0].Checkpoint)
+
+	assert.Nil(t, inst.recordCheckpoint())
+	assert.Len(t, recorder.snapshots, 1)
+}
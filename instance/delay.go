@@ -0,0 +1,31 @@
+package instance
+
+import "time"
+
+// DelayContext is implemented by the activity.Context passed to activities executed by a flow,
+// it allows an activity to delay the execution of the rest of the instance once the current
+// step completes.
+type DelayContext interface {
+	Delay(d time.Duration)
+}
+
+// Delay implements DelayContext.Delay
+func (ti *TaskInst) Delay(d time.Duration) {
+	ti.logger.Debugf("Task[%s] - Delaying instance for: %s", ti.taskID, d)
+	ti.flowInst.master.delayUntil = time.Now().Add(d)
+}
+
+func (l *LegacyCtx) Delay(d time.Duration) {
+	l.task.Delay(d)
+}
+
+// DelayUntil returns the time until which the execution of the instance has been delayed, the
+// zero time indicates that the instance isn't delayed
+func (inst *IndependentInstance) DelayUntil() time.Time {
+	return inst.delayUntil
+}
+
+// ClearDelay clears the delayed state of the instance so that it can continue executing
+func (inst *IndependentInstance) ClearDelay() {
+	inst.delayUntil = time.Time{}
+}
@@ -0,0 +1,48 @@
+package instance
+
+import (
+	"fmt"
+
+	"github.com/project-flogo/core/data/coerce"
+)
+
+// ErrorResultKey is the key of the return data used by return tasks to set a structured
+// error, its value is an object with 'code', 'message' and optional 'details'
+const ErrorResultKey = "_error"
+
+// ErrorWithCode is an error that carries a code (ex. an HTTP status) and details, so that
+// triggers can map a flow error without parsing its message
+type ErrorWithCode struct {
+	Code    string                 `json:"code"`
+	Message string                 `json:"message"`
+	Details map[string]interface{} `json:"details,omitempty"`
+}
+
+// NewErrorWithCode creates a new ErrorWithCode
+func NewErrorWithCode(code, message string, details map[string]interface{}) *ErrorWithCode {
+	return &ErrorWithCode{Code: code, Message: message, Details: details}
+}
+
+func (e *ErrorWithCode) Error() string {
+	return fmt.Sprintf("[%s] %s", e.Code, e.Message)
+}
+
+// toErrorWithCode converts the value set for ErrorResultKey to an ErrorWithCode
+func toErrorWithCode(val interface{}) (*ErrorWithCode, error) {
+	obj, err := coerce.ToObject(val)
+	if err != nil {
+		return nil, fmt.Errorf("invalid error result: %s", err.Error())
+	}
+
+	e := &ErrorWithCode{}
+	e.Code, _ = coerce.ToString(obj["code"])
+	e.Message, _ = coerce.ToString(obj["message"])
+	if details, ok := obj["details"]; ok && details != nil {
+		e.Details, err = coerce.ToObject(details)
+		if err != nil {
+			return nil, fmt.Errorf("invalid error result details: %s", err.Error())
+		}
+	}
+
+	return e, nil
+}
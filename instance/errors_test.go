@@ -0,0 +1,31 @@
+package instance
+
+import (
+	"testing"
+
+	"github.com/project-flogo/core/support/log"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReturnErrorWithCode(t *testing.T) {
+	inst, err := NewIndependentInstance("test", "", getDef(), nil, log.RootLogger())
+	assert.Nil(t, err)
+
+	inst.Return(map[string]interface{}{
+		"result": "none",
+		ErrorResultKey: map[string]interface{}{
+			"code":    "404",
+			"message": "pet not found",
+			"details": map[string]interface{}{"id": 1},
+		},
+	}, nil)
+
+	data, err := inst.GetReturnData()
+	assert.Equal(t, map[string]interface{}{"result": "none"}, data)
+
+	errWithCode, ok := err.(*ErrorWithCode)
+	assert.True(t, ok)
+	assert.Equal(t, "404", errWithCode.Code)
+	assert.Equal(t, "pet not found", errWithCode.Message)
+	assert.Equal(t, 1, errWithCode.Details["id"])
+}
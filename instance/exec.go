@@ -1,6 +1,10 @@
 package instance
 
 import (
+	"time"
+
+	"github.com/project-flogo/core/support/trace"
+	"github.com/project-flogo/flow/definition"
 	"github.com/project-flogo/flow/support"
 )
 
@@ -20,12 +24,107 @@ type RunOptions struct {
 	InitialState        *IndependentInstance
 	ExecOptions         *ExecOptions
 	Rerun               bool
+	// ResumeToken is used with OpResume to resume the instance suspended with the token,
+	// instead of providing the InitialState
+	ResumeToken string
+	// SubflowOptions are set when the flow is started by another flow
+	SubflowOptions *SubflowOptions
+	// Labels are attached to the instance and included in its metrics, introspection info and
+	// recorded state.  Each distinct label value creates a new metric series, so labels should
+	// only use values from a small, bounded set (ex. tenant or region, never a request ID).
+	Labels map[string]string
+	// AttrOverrides are used with OpResume and OpRestart to patch specific attributes of the
+	// instance before it continues, they are applied after the inputs and must be known to the
+	// flow (see IndependentInstance.ValidateAttrs).  The inputs the instance was started with
+	// are available using IndependentInstance.Inputs.
+	AttrOverrides map[string]interface{}
+	// CorrelationID is shared by all the flows of a business transaction and is included in the
+	// instance's log fields, it should be passed on to the flows the instance starts.  A new ID is
+	// generated when a flow is started without one.
+	CorrelationID string
+	// Definition is used with OpResume to resume the instance with a corrected definition for
+	// the remaining steps, the tasks already executed must be unchanged (see
+	// IndependentInstance.UpdateDefinition)
+	Definition *definition.Definition
+	// RetryCount is the number of times the flow has been retried, it is set by the engine when
+	// retrying a flow (see ExecOptions.FlowRetry)
+	RetryCount int
+	// Priority orders the instances waiting to run when the engine's maximum number of concurrent
+	// instances is reached, instances with a higher priority run first
+	Priority int
+}
+
+// SubflowOptions are the options used when a flow is started by another flow
+type SubflowOptions struct {
+	// ParentTracingContext is used as the parent span of the started flow's trace
+	ParentTracingContext trace.TracingContext
+	// ParentInstanceID is the ID of the instance that started the flow
+	ParentInstanceID string
+	// ParentFlowName is the name of the flow that started the flow
+	ParentFlowName string
 }
 
 // ExecOptions are optional Patch & Interceptor to be used during instance execution
 type ExecOptions struct {
 	Patch       *support.Patch
 	Interceptor *support.Interceptor
+
+	// ActivityOverrides are evaluated instead of the activities with the corresponding refs
+	ActivityOverrides map[string]ActivityFunc
+
+	// MaxDuration is the maximum amount of time the instance is allowed to run, after
+	// which it is failed.  It is checked before each step and is the deadline of the
+	// context provided to activities (see GoContext).  It overrides the flow's DefaultTimeout
+	// setting, which overrides the engine's defaultFlowTimeout.
+	MaxDuration time.Duration
+
+	// ResultBufferSize is the number of results that are buffered for delivery to the result handler
+	ResultBufferSize int
+	// ResultTimeout is how long the instance waits for room in the result buffer, after which
+	// the result is dropped and the result handler is eventually delivered an error instead
+	ResultTimeout time.Duration
+
+	// FlowRetry retries the whole flow, as a new instance with the same inputs, when the instance fails
+	FlowRetry *FlowRetry
+
+	// PauseOnError suspends the instance, using its ID as the token, when a task fails and the
+	// error isn't handled by the flow.  The failed task is evaluated again when the instance is
+	// resumed, ex. after correcting its inputs using RunOptions.AttrOverrides.
+	PauseOnError bool
+
+	// AttributeWatcher is notified of the attributes written by the instance's tasks
+	AttributeWatcher AttributeWatcher
+
+	// Envelope wraps the results delivered to the result handler in an envelope with the execution
+	// metadata of the instance: {"data": {...}, "meta": {"instanceId", "durationMs", "steps", "status"}}
+	Envelope bool
+
+	// LogID is a display ID, ex. a short human readable ID, used in place of the instance ID in the
+	// logs and introspection info of the instance.  The recorded state uses the instance ID.
+	LogID string
+
+	// FireAndForget returns the ID of the instance as soon as it is started, as with
+	// RunOptions.ReturnID, and lets it run to completion in the background.  The results of the
+	// instance are not delivered to the result handler, they are only available from the state
+	// recorder when the instance's state is recorded.  The handler's Done is still called once the
+	// instance is done.
+	FireAndForget bool
+
+	// StartTimeout bounds how long Run waits for the instance to be allowed to run when the
+	// engine's maximum number of concurrent instances is reached, Run fails with a start timed out
+	// error once it expires.  Without it Run returns immediately and the instance waits to run.
+	StartTimeout time.Duration
+}
+
+// FlowRetry configures the retry of a whole flow when its instance fails
+type FlowRetry struct {
+	// MaxAttempts is the maximum number of times the flow is retried
+	MaxAttempts int
+	// ErrorClasses are the classes of error that trigger a retry, an error's class is its code if it
+	// has one, otherwise its type.  All errors trigger a retry when empty.
+	ErrorClasses []string
+	// Backoff is the delay before the first retry, it is doubled for each subsequent retry
+	Backoff time.Duration
 }
 
 // IDGenerator generates IDs for flow instances
@@ -50,6 +149,15 @@ func ApplyExecOptions(instance *IndependentInstance, execOptions *ExecOptions) {
 			instance.interceptor = execOptions.Interceptor
 			instance.interceptor.Init()
 		}
+
+		if len(execOptions.ActivityOverrides) > 0 {
+			instance.logger.Debugf("Instance [%s] has activity overrides", instance.ID())
+			instance.activityOverrides = execOptions.ActivityOverrides
+		}
+
+		instance.pauseOnError = execOptions.PauseOnError
+		instance.attrWatcher = execOptions.AttributeWatcher
+		instance.logID = execOptions.LogID
 	}
 }
 
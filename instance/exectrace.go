@@ -0,0 +1,87 @@
+package instance
+
+import (
+	"time"
+
+	"github.com/project-flogo/flow/model"
+)
+
+// StepRecord describes the task executed by a step of a flow instance
+type StepRecord struct {
+	StepID    int              `json:"stepId"`
+	TaskID    string           `json:"taskId"`
+	TaskName  string           `json:"taskName"`
+	SubflowID int              `json:"subflowId,omitempty"`
+	Status    model.TaskStatus `json:"status"`
+	StartTime time.Time        `json:"startTime"`
+	EndTime   time.Time        `json:"endTime"`
+	// NextTasks are the tasks entered once this task finished, more than one indicates a fork
+	NextTasks []string `json:"nextTasks,omitempty"`
+}
+
+// Duration returns how long the task took to execute
+func (r *StepRecord) Duration() time.Duration {
+	return r.EndTime.Sub(r.StartTime)
+}
+
+// DefaultMaxExecTraceSteps is the default maximum number of steps kept in the execution trace of an
+// instance
+const DefaultMaxExecTraceSteps = 1000
+
+var maxExecTraceSteps = DefaultMaxExecTraceSteps
+
+// SetMaxExecTraceSteps sets the maximum number of steps kept in the execution trace of an instance,
+// the oldest steps are dropped once it is reached.  0 restores the default, a negative value
+// disables the trace.
+func SetMaxExecTraceSteps(max int) {
+	if max == 0 {
+		max = DefaultMaxExecTraceSteps
+	}
+	maxExecTraceSteps = max
+}
+
+// ExecutionTrace returns the ordered list of tasks executed by the instance so far, unlike the
+// state recorder this is always available.  Only the latest steps are kept in long running
+// instances (see SetMaxExecTraceSteps).
+func (inst *IndependentInstance) ExecutionTrace() []StepRecord {
+	records := make([]StepRecord, len(inst.execTrace))
+	for i, r := range inst.execTrace {
+		records[i] = *r
+	}
+	return records
+}
+
+func (inst *IndependentInstance) startStepRecord(taskInst *TaskInst) {
+	if maxExecTraceSteps < 0 {
+		return
+	}
+	inst.currStepRecord = &StepRecord{
+		StepID:    inst.stepID,
+		TaskID:    taskInst.taskID,
+		TaskName:  taskInst.task.Name(),
+		SubflowID: taskInst.flowInst.subflowId,
+		StartTime: time.Now().UTC(),
+	}
+}
+
+func (inst *IndependentInstance) finishStepRecord(taskInst *TaskInst) {
+	r := inst.currStepRecord
+	if r == nil {
+		return
+	}
+	r.Status = taskInst.status
+	r.EndTime = time.Now().UTC()
+
+	if len(inst.execTrace) >= maxExecTraceSteps {
+		inst.execTrace = inst.execTrace[len(inst.execTrace)-maxExecTraceSteps+1:]
+	}
+	inst.execTrace = append(inst.execTrace, r)
+	inst.currStepRecord = nil
+}
+
+// recordTaskEntered notes that a task was entered by the task currently being executed
+func (inst *IndependentInstance) recordTaskEntered(taskID string) {
+	if inst.currStepRecord != nil {
+		inst.currStepRecord.NextTasks = append(inst.currStepRecord.NextTasks, taskID)
+	}
+}
@@ -0,0 +1,61 @@
+package instance
+
+import (
+	"strings"
+
+	"github.com/project-flogo/core/data/coerce"
+)
+
+const flagsPrefix = "_flags."
+
+// FeatureFlagResolver resolves the feature flags available to flows as $flags.<name>
+type FeatureFlagResolver interface {
+	// ResolveFlag returns the value of the flag for the specified flow instance
+	ResolveFlag(flowName, instanceID, flag string) (interface{}, error)
+}
+
+var featureFlagResolver FeatureFlagResolver
+
+// SetFeatureFlagResolver sets the FeatureFlagResolver, nil disables feature flags
+func SetFeatureFlagResolver(resolver FeatureFlagResolver) {
+	featureFlagResolver = resolver
+}
+
+// featureFlag resolves the flag, the value is cached so that it doesn't change during the
+// lifetime of the instance
+func (inst *IndependentInstance) featureFlag(flag string) (interface{}, bool) {
+	if value, cached := inst.flags[flag]; cached {
+		return value, true
+	}
+
+	if featureFlagResolver == nil {
+		return nil, false
+	}
+
+	value, err := featureFlagResolver.ResolveFlag(inst.Name(), inst.id, flag)
+	if err != nil {
+		inst.logger.Warnf("Unable to resolve feature flag '%s' for instance [%s]: %v", flag, inst.id, err)
+		return nil, false
+	}
+
+	if inst.flags == nil {
+		inst.flags = make(map[string]interface{})
+	}
+	inst.flags[flag] = value
+
+	return value, true
+}
+
+// featureFlagOn indicates whether the flag is on, a flag that can't be resolved is off
+func (inst *IndependentInstance) featureFlagOn(flag string) bool {
+	value, ok := inst.featureFlag(flag)
+	if !ok {
+		return false
+	}
+	on, _ := coerce.ToBool(value)
+	return on
+}
+
+func isFlag(name string) bool {
+	return strings.HasPrefix(name, flagsPrefix)
+}
@@ -0,0 +1,52 @@
+package instance
+
+import (
+	"testing"
+
+	"github.com/project-flogo/core/support/log"
+	"github.com/stretchr/testify/assert"
+)
+
+type testFlagResolver struct {
+	calls int
+	value bool
+}
+
+func (r *testFlagResolver) ResolveFlag(flowName, instanceID, flag string) (interface{}, error) {
+	r.calls++
+	return r.value, nil
+}
+
+func TestFeatureFlag(t *testing.T) {
+	resolver := &testFlagResolver{value: true}
+	SetFeatureFlagResolver(resolver)
+	defer SetFeatureFlagResolver(nil)
+
+	inst, err := NewIndependentInstance("test", "", getDef(), nil, log.RootLogger())
+	assert.Nil(t, err)
+
+	value, exists := inst.GetValue("_flags.newCheckout")
+	assert.True(t, exists)
+	assert.Equal(t, true, value)
+
+	// the value is cached for the lifetime of the instance
+	resolver.value = false
+	value, _ = inst.GetValue("_flags.newCheckout")
+	assert.Equal(t, true, value)
+	assert.Equal(t, 1, resolver.calls)
+}
+
+func TestFeatureFlagOn(t *testing.T) {
+	inst, err := NewIndependentInstance("test", "", getDef(), nil, log.RootLogger())
+	assert.Nil(t, err)
+
+	// flags are off when they can't be resolved
+	assert.False(t, inst.featureFlagOn("newCheckout"))
+
+	SetFeatureFlagResolver(&testFlagResolver{value: true})
+	defer SetFeatureFlagResolver(nil)
+
+	inst, err = NewIndependentInstance("test", "", getDef(), nil, log.RootLogger())
+	assert.Nil(t, err)
+	assert.True(t, inst.featureFlagOn("newCheckout"))
+}
@@ -0,0 +1,37 @@
+package instance
+
+import "context"
+
+// GoContext is implemented by the activity.Context passed to activities executed by a flow, it
+// provides the context.Context of the instance.  The context lives as long as the instance, it is
+// cancelled when the instance finishes or is cancelled, not when the trigger's request is done.
+// When the instance has a maximum duration (see ExecOptions.MaxDuration) the context's deadline is
+// the end of that duration, so activities can abort slow I/O instead of only being stopped at the
+// next step boundary.  Steps don't have timeouts of their own, an activity that bounds its work
+// should derive its timeout from this context (ex. context.WithTimeout), so that the earlier of
+// the two deadlines applies.
+type GoContext interface {
+	GoContext() context.Context
+}
+
+// GoContext implements GoContext.GoContext
+func (ti *TaskInst) GoContext() context.Context {
+	return ti.flowInst.master.GoContext()
+}
+
+func (l *LegacyCtx) GoContext() context.Context {
+	return l.task.GoContext()
+}
+
+// SetGoContext sets the context.Context provided to the activities of the instance
+func (inst *IndependentInstance) SetGoContext(ctx context.Context) {
+	inst.goCtx = ctx
+}
+
+// GoContext returns the context.Context provided to the activities of the instance
+func (inst *IndependentInstance) GoContext() context.Context {
+	if inst.goCtx == nil {
+		return context.Background()
+	}
+	return inst.goCtx
+}
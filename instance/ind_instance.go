@@ -1,9 +1,12 @@
 package instance
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"sort"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/project-flogo/flow/state"
@@ -31,6 +34,32 @@ type IndependentInstance struct {
 	patch       *flowsupport.Patch
 	interceptor *flowsupport.Interceptor
 
+	activityOverrides map[string]ActivityFunc
+	retryCount        int
+	correlationID     string
+	flags             map[string]interface{}
+	inputs            map[string]interface{}
+	pauseOnError      bool
+	pausedTask        *TaskInst
+	pausedError       error
+	attrWatcher       AttributeWatcher
+	logID             string
+	taskOutputs       map[string]*cachedTaskOutputs
+	taskStates        map[string]TaskStatus
+
+	execTrace      []*StepRecord
+	currStepRecord *StepRecord
+
+	goCtx        context.Context
+	suspendToken string
+	suspendedAt  time.Time
+	checkpoint   string
+	delayUntil   time.Time
+	labels       map[string]string
+
+	// traceSampledOut indicates that the instance isn't traced, nor are its tasks and subflows
+	traceSampledOut bool
+
 	subflowCtr int
 	subflows   map[int]*Instance
 	startTime  time.Time
@@ -44,6 +73,8 @@ const (
 	flowId         = "FlowId"
 	parentFlowName = "ParentFlowName"
 	parentFlowId   = "ParentFlowId"
+	retryCount     = "RetryCount"
+	correlationId  = "CorrelationId"
 )
 
 // New creates a new Flow Instance from the specified Flow
@@ -96,7 +127,7 @@ func (inst *IndependentInstance) newEmbeddedInstance(taskInst *TaskInst, flowURI
 	embeddedInst.flowURI = flowURI
 	embeddedInst.logger = inst.logger
 
-	if trace.Enabled() {
+	if inst.traced() {
 		tc, _ := trace.GetTracer().StartTrace(embeddedInst.SpanConfig(), taskInst.traceContext) //TODO handle error
 		embeddedInst.tracingCtx = tc
 	}
@@ -120,6 +151,11 @@ func (inst *IndependentInstance) ExecutionTime() time.Duration {
 	return time.Since(inst.startTime)
 }
 
+// StartTime returns the time the instance was started
+func (inst *IndependentInstance) StartTime() time.Time {
+	return inst.startTime
+}
+
 func (inst *IndependentInstance) GetFlowState(inputs map[string]interface{}) *state.FlowState {
 	return &state.FlowState{
 		UserId:         flowsupport.GetUserName(),
@@ -131,13 +167,119 @@ func (inst *IndependentInstance) GetFlowState(inputs map[string]interface{}) *st
 		FlowStats:      string(convertFlowStatus(inst.status)),
 		StartTime:      inst.startTime,
 		EndTime:        time.Now().UTC(),
+		Labels:         inst.labels,
+	}
+}
+
+// MaxLabels is the maximum number of labels that can be attached to an instance
+const MaxLabels = 10
+
+// SetLabels sets the labels of the instance, labels are not part of the instance's attributes.
+// Only MaxLabels labels are kept to bound the cardinality of the metrics they are used in.
+func (inst *IndependentInstance) SetLabels(labels map[string]string) {
+	if len(labels) == 0 {
+		return
+	}
+
+	names := make([]string, 0, len(labels))
+	for name := range labels {
+		names = append(names, name)
 	}
+	if len(names) > MaxLabels {
+		sort.Strings(names)
+		inst.logger.Warnf("Instance [%s] has %d labels, only the first %d are kept", inst.id, len(names), MaxLabels)
+		names = names[:MaxLabels]
+	}
+
+	inst.labels = make(map[string]string, len(names))
+	for _, name := range names {
+		inst.labels[name] = labels[name]
+	}
+}
+
+// Labels returns the labels of the instance
+func (inst *IndependentInstance) Labels() map[string]string {
+	return inst.labels
+}
+
+// SetRetryCount sets the number of times the flow has been retried, it is available to the flow
+// as the '_fctx.RetryCount' attribute
+func (inst *IndependentInstance) SetRetryCount(count int) {
+	inst.retryCount = count
+}
+
+// RetryCount returns the number of times the flow has been retried
+func (inst *IndependentInstance) RetryCount() int {
+	return inst.retryCount
+}
+
+// SetCorrelationID sets the ID shared by all the flows of a business transaction, it is available
+// to the flow and its subflows as the '_fctx.CorrelationId' attribute
+func (inst *IndependentInstance) SetCorrelationID(id string) {
+	inst.correlationID = id
+}
+
+// CorrelationID returns the ID shared by all the flows of a business transaction
+func (inst *IndependentInstance) CorrelationID() string {
+	return inst.correlationID
+}
+
+// LogID returns the ID the instance is identified by in logs, it is the instance ID unless a
+// display ID was provided using ExecOptions.LogID.  The recorded state always uses the instance ID.
+func (inst *IndependentInstance) LogID() string {
+	if inst.logID != "" {
+		return inst.logID
+	}
+	return inst.id
+}
+
+// ValidateAttrs checks that the specified attributes are known to the instance, an attribute is
+// known if it is a flow input or output, or the instance already has a value for it
+func (inst *IndependentInstance) ValidateAttrs(attrs map[string]interface{}) error {
+	md := inst.flowDef.Metadata()
+
+	var unknown []string
+	for name := range attrs {
+		if _, exists := inst.attrs[name]; exists {
+			continue
+		}
+		if md != nil {
+			if _, exists := md.Input[name]; exists {
+				continue
+			}
+			if _, exists := md.Output[name]; exists {
+				continue
+			}
+		}
+		unknown = append(unknown, name)
+	}
+
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return fmt.Errorf("unknown attributes for flow instance [%s]: %s", inst.id, strings.Join(unknown, ", "))
+	}
+
+	return nil
 }
 
 func (inst *IndependentInstance) Start(startAttrs map[string]interface{}) bool {
+	inst.inputs = make(map[string]interface{}, len(startAttrs))
+	for name, value := range startAttrs {
+		inst.inputs[name] = value
+	}
 	return inst.startInstance(inst.Instance, startAttrs)
 }
 
+// Inputs returns a copy of the inputs the instance was started with, when resuming an instance
+// specific inputs can be replaced using RunOptions.AttrOverrides
+func (inst *IndependentInstance) Inputs() map[string]interface{} {
+	inputs := make(map[string]interface{}, len(inst.inputs))
+	for name, value := range inst.inputs {
+		inputs[name] = value
+	}
+	return inputs
+}
+
 func (inst *IndependentInstance) startEmbedded(embedded *Instance, startAttrs map[string]interface{}) error {
 
 	if embedded.master != inst {
@@ -177,6 +319,9 @@ func (inst *IndependentInstance) startInstance(toStart *Instance, startAttrs map
 		_ = toStart.SetValue(flowCtxPrefix+parentFlowId, "")
 	}
 
+	_ = toStart.SetValue(flowCtxPrefix+retryCount, inst.retryCount)
+	_ = toStart.SetValue(flowCtxPrefix+correlationId, inst.correlationID)
+
 	md := toStart.flowDef.Metadata()
 
 	if md != nil && md.Input != nil {
@@ -233,6 +378,18 @@ func (inst *IndependentInstance) ApplyInterceptor(interceptor *flowsupport.Inter
 	}
 }
 
+// Fail fails the instance with the specified error, without invoking the error handler
+func (inst *IndependentInstance) Fail(err error) {
+	inst.returnError = err
+	inst.SetStatus(model.FlowStatusFailed)
+}
+
+// Cancel cancels the instance
+func (inst *IndependentInstance) Cancel() {
+	inst.returnError = fmt.Errorf("flow instance [%s] was cancelled", inst.id)
+	inst.SetStatus(model.FlowStatusCancelled)
+}
+
 // GetChanges returns the Change Tracker object
 func (inst *IndependentInstance) GetChanges() ChangeTracker {
 	return inst.changeTracker
@@ -248,6 +405,21 @@ func (inst *IndependentInstance) StepID() int {
 	return inst.stepID
 }
 
+// NextTaskID returns the ID of the task that will be executed by the next step,
+// or an empty string if there is no pending work
+func (inst *IndependentInstance) NextTaskID() string {
+	e := inst.workItemQueue.List.Front()
+	if e == nil {
+		return ""
+	}
+
+	if workItem, ok := e.Value.(*WorkItem); ok {
+		return workItem.TaskID
+	}
+
+	return ""
+}
+
 func (inst *IndependentInstance) DoStep() bool {
 
 	hasNext := false
@@ -276,7 +448,9 @@ func (inst *IndependentInstance) DoStep() bool {
 			// track the fact that the work item was removed from the queue
 			inst.changeTracker.WorkItemRemoved(workItem)
 
+			inst.startStepRecord(workItem.taskInst)
 			inst.execTask(behavior, workItem.taskInst)
+			inst.finishStepRecord(workItem.taskInst)
 
 			hasNext = true
 		} else {
@@ -392,6 +566,10 @@ func (inst *IndependentInstance) handleTaskDone(taskBehavior model.TaskBehavior,
 		if taskInst.traceContext != nil {
 			_ = trace.GetTracer().FinishTrace(taskInst.traceContext, nil)
 		}
+		if taskInst.skipped {
+			// the task's skipIf expression was true or its feature flag was off, its links were still followed
+			taskInst.SetStatus(model.TaskStatusSkipped)
+		}
 	}
 
 	if err != nil {
@@ -554,6 +732,9 @@ func (inst *IndependentInstance) handleTaskError(taskBehavior model.TaskBehavior
 
 		} else {
 			taskInst.appendErrorData(err)
+			if inst.pauseOnTaskError(taskInst, err) {
+				return
+			}
 			inst.HandleGlobalError(containerInst, err)
 		}
 		return
@@ -561,6 +742,34 @@ func (inst *IndependentInstance) handleTaskError(taskBehavior model.TaskBehavior
 
 }
 
+// HandleStepError routes an error raised while stepping the instance, rather than by one of its
+// tasks, to the flow's error handler.  The error is exposed to the handler's mappings as $error,
+// with the id of the task being executed as its activity.  If the flow has no error handler, or
+// is already handling an error, the instance is failed and false is returned.
+func (inst *IndependentInstance) HandleStepError(taskID string, err error) bool {
+
+	if inst.isHandlingError || inst.flowDef.GetErrorHandler() == nil {
+		inst.Fail(err)
+		return false
+	}
+
+	errObj := NewErrorObj(taskID, err.Error())
+	_ = inst.SetValue("_E", errObj)
+	if taskID != "" {
+		_ = inst.SetValue("_E."+taskID, errObj)
+	}
+
+	// the remaining work of the failed flow is abandoned in favor of the error handler
+	for {
+		if _, ok := inst.workItemQueue.Pop(); !ok {
+			break
+		}
+	}
+
+	inst.HandleGlobalError(inst.Instance, err)
+	return inst.Status() != model.FlowStatusFailed
+}
+
 // HandleGlobalError handles instance errors
 func (inst *IndependentInstance) HandleGlobalError(containerInst *Instance, err error) {
 
@@ -633,6 +842,7 @@ func (inst *IndependentInstance) enterTasks(activeInst *Instance, taskEntries []
 		behavior := inst.flowModel.GetTaskBehavior(taskEntry.Task.TypeID())
 		taskInst, _ := activeInst.FindOrCreateTaskInst(taskEntry.Task)
 		taskInst.id = taskInst.taskID
+		inst.recordTaskEntered(taskInst.taskID)
 
 		enterResult := behavior.Enter(taskInst)
 
@@ -729,6 +939,11 @@ func (inst *IndependentInstance) Restart(logger log.Logger, id string, initStepI
 	inst.master = inst
 	inst.init(inst.Instance)
 
+	if initStepId > 0 {
+		// keep the step numbering consistent with the recorded steps
+		inst.stepID = initStepId
+	}
+
 	inst.changeTracker = NewInstanceChangeTracker(inst.id, initStepId)
 	inst.changeTracker.FlowCreated(inst)
 	// Set flow status to active
@@ -786,6 +1001,17 @@ func (inst *IndependentInstance) SetTracingContext(tracingCtx trace.TracingConte
 	inst.tracingCtx = tracingCtx
 }
 
+// SetTraceSampled sets whether the instance was sampled to be traced, the tasks and subflows of an
+// instance that isn't sampled aren't traced either
+func (inst *IndependentInstance) SetTraceSampled(sampled bool) {
+	inst.traceSampledOut = !sampled
+}
+
+// traced indicates if the tasks and subflows of the instance are traced
+func (inst *IndependentInstance) traced() bool {
+	return trace.Enabled() && !inst.traceSampledOut
+}
+
 func (inst *Instance) SpanConfig() trace.Config {
 	config := trace.Config{}
 	config.Operation = inst.Name()
@@ -812,6 +1038,17 @@ func (inst *IndependentInstance) Snapshot() *state.Snapshot {
 
 	populateBaseSnapshot(inst.Instance, fs.SnapshotBase)
 
+	if inst.pausedTask != nil {
+		fs.PausedTaskId = inst.pausedTask.taskID
+		fs.PausedError = inst.pausedError.Error()
+	}
+
+	for e := inst.workItemQueue.List.Front(); e != nil; e = e.Next() {
+		if wi, ok := e.Value.(*WorkItem); ok {
+			fs.WorkQueue = append(fs.WorkQueue, &state.WorkItem{ID: wi.ID, SubflowId: wi.SubFlowID, TaskId: wi.TaskID})
+		}
+	}
+
 	if len(inst.subflows) > 0 {
 		fs.Subflows = make([]*state.Subflow, 0, len(inst.subflows))
 		for id, subflow := range inst.subflows {
@@ -834,7 +1071,7 @@ func populateBaseSnapshot(inst *Instance, base *state.SnapshotBase) {
 	if len(inst.attrs) > 0 {
 		base.Attrs = make(map[string]interface{}, len(inst.attrs))
 		for name, value := range inst.attrs {
-			base.Attrs[name] = value
+			base.Attrs[name] = flowsupport.RecordableValue(value)
 		}
 	}
 
@@ -3,6 +3,7 @@ package instance
 import (
 	"encoding/json"
 	"fmt"
+	"strings"
 	"testing"
 
 	"github.com/project-flogo/core/activity"
@@ -10,6 +11,7 @@ import (
 	"github.com/project-flogo/core/data/metadata"
 	"github.com/project-flogo/core/support/log"
 	"github.com/project-flogo/flow/definition"
+	"github.com/project-flogo/flow/model"
 	"github.com/stretchr/testify/assert"
 )
 func init() {
@@ -105,4 +107,217 @@ func getDef() *definition.Definition {
 	def, _ := definition.NewDefinition(defRep)
 
 	return def
-}
\ No newline at end of file
+}
+func TestSetLabels(t *testing.T) {
+	inst, err := NewIndependentInstance("test", "", getDef(), nil, log.RootLogger())
+	assert.Nil(t, err)
+
+	inst.SetLabels(nil)
+	assert.Nil(t, inst.Labels())
+
+	inst.SetLabels(map[string]string{"tenant": "acme", "region": "us-west"})
+	assert.Equal(t, map[string]string{"tenant": "acme", "region": "us-west"}, inst.Labels())
+	assert.Equal(t, "acme", inst.GetFlowState(nil).Labels["tenant"])
+
+	labels := make(map[string]string)
+	for i := 0; i < MaxLabels+5; i++ {
+		labels[fmt.Sprintf("label%02d", i)] = "value"
+	}
+	inst.SetLabels(labels)
+	assert.Len(t, inst.Labels(), MaxLabels)
+	_, exists := inst.Labels()[fmt.Sprintf("label%02d", MaxLabels)]
+	assert.False(t, exists)
+}
+
+func TestValidateAttrs(t *testing.T) {
+	inst, err := NewIndependentInstance("test", "", getDef(), nil, log.RootLogger())
+	assert.Nil(t, err)
+
+	assert.NotNil(t, inst.ValidateAttrs(map[string]interface{}{"unknown": 1}))
+
+	_ = inst.SetValue("count", 1)
+	assert.Nil(t, inst.ValidateAttrs(map[string]interface{}{"count": 2}))
+}
+
+func TestUpdateDefinition(t *testing.T) {
+	inst, err := NewIndependentInstance("test", "", getDef(), nil, log.RootLogger())
+	assert.Nil(t, err)
+	inst.execTrace = []*StepRecord{{StepID: 1, TaskID: "LogStart"}}
+
+	newDef := func(from, to string) *definition.Definition {
+		defRep := &definition.DefinitionRep{}
+		_ = json.Unmarshal([]byte(strings.Replace(defTestJSON, from, to, 1)), defRep)
+		def, _ := definition.NewDefinition(defRep)
+		return def
+	}
+
+	err = inst.UpdateDefinition(newDef("Log Results", "Log Pet"))
+	assert.Nil(t, err)
+
+	err = inst.UpdateDefinition(newDef("Find Pet Flow Started!", "Flow Started"))
+	assert.NotNil(t, err)
+
+	// a restored instance has no execution trace, its task states are checked
+	inst, err = NewIndependentInstance("test", "", getDef(), nil, log.RootLogger())
+	assert.Nil(t, err)
+	inst.taskStates = map[string]TaskStatus{"LogStart": TaskStatusDone}
+	data, err := json.Marshal(inst)
+	assert.Nil(t, err)
+	restored := &IndependentInstance{}
+	assert.Nil(t, json.Unmarshal(data, restored))
+	restored.flowDef = getDef()
+	assert.Nil(t, restored.execTrace)
+
+	err = restored.UpdateDefinition(newDef("Find Pet Flow Started!", "Flow Started"))
+	assert.NotNil(t, err)
+}
+
+func TestTraceHeadersNoTracer(t *testing.T) {
+	inst, err := NewIndependentInstance("test", "", getDef(), nil, log.RootLogger())
+	assert.Nil(t, err)
+	assert.Nil(t, inst.TraceHeaders())
+}
+
+func TestHandleStepErrorNoHandler(t *testing.T) {
+	inst, err := NewIndependentInstance("test", "", getDef(), nil, log.RootLogger())
+	assert.Nil(t, err)
+
+	assert.False(t, inst.HandleStepError("LogStart", fmt.Errorf("step failed")))
+	assert.Equal(t, model.FlowStatusFailed, inst.Status())
+	assert.NotNil(t, inst.returnError)
+}
+
+func TestInputs(t *testing.T) {
+	inst, err := NewIndependentInstance("test", "", getDef(), nil, log.RootLogger())
+	assert.Nil(t, err)
+	assert.Empty(t, inst.Inputs())
+
+	inst.inputs = map[string]interface{}{"petId": "1"}
+	inputs := inst.Inputs()
+	inputs["petId"] = "2"
+	assert.Equal(t, "1", inst.Inputs()["petId"])
+
+	b, err := json.Marshal(inst)
+	assert.Nil(t, err)
+	restored := &IndependentInstance{}
+	assert.Nil(t, json.Unmarshal(b, restored))
+	assert.Equal(t, map[string]interface{}{"petId": "1"}, restored.Inputs())
+	assert.True(t, restored.SuspendedAt().IsZero())
+
+	inst.Suspend("token")
+	b, err = json.Marshal(inst)
+	assert.Nil(t, err)
+	restored = &IndependentInstance{}
+	assert.Nil(t, json.Unmarshal(b, restored))
+	assert.True(t, inst.SuspendedAt().Equal(restored.SuspendedAt()))
+}
+
+func TestSequenceDiagram(t *testing.T) {
+	inst, err := NewIndependentInstance("test", "", getDef(), nil, log.RootLogger())
+	assert.Nil(t, err)
+
+	inst.execTrace = []*StepRecord{
+		{StepID: 1, TaskID: "start", TaskName: "Start", Status: model.TaskStatusDone, NextTasks: []string{"a", "b"}},
+		{StepID: 2, TaskID: "a", TaskName: "A", Status: model.TaskStatusDone, NextTasks: []string{"a"}},
+		{StepID: 3, TaskID: "b", TaskName: "B", Status: model.TaskStatusFailed},
+		{StepID: 4, TaskID: "a", TaskName: "A", Status: model.TaskStatusDone},
+	}
+
+	diagram := inst.SequenceDiagram()
+	assert.True(t, strings.HasPrefix(diagram, "sequenceDiagram\n"))
+	assert.Contains(t, diagram, "participant t1 as Start\n")
+	assert.Contains(t, diagram, "flow->>t1: step 1\n")
+	assert.Contains(t, diagram, "Note over t1: fork to a, b\n")
+	assert.Contains(t, diagram, "t1->>t2: step 2\n")
+	assert.Contains(t, diagram, "t1->>t3: step 3\n")
+	assert.Contains(t, diagram, "Note over t3: failed\n")
+	assert.Contains(t, diagram, "t2->>t2: step 4 (run 2)\n")
+}
+
+func TestCachedTaskOutputs(t *testing.T) {
+	inst, err := NewIndependentInstance("test", "", getDef(), nil, log.RootLogger())
+	assert.Nil(t, err)
+	taskInst := &TaskInst{flowInst: inst.Instance, taskID: "lookup"}
+
+	hash, ok := taskInputsHash(map[string]interface{}{"id": "1"})
+	assert.True(t, ok)
+	_, cached := inst.cachedOutputs(taskInst, hash)
+	assert.False(t, cached)
+
+	inst.cacheOutputs(taskInst, hash, map[string]interface{}{"name": "flogo"})
+	outputs, cached := inst.cachedOutputs(taskInst, hash)
+	assert.True(t, cached)
+	assert.Equal(t, "flogo", outputs["name"])
+
+	// the cached outputs are reset when the inputs change
+	changed, _ := taskInputsHash(map[string]interface{}{"id": "2"})
+	inst.cacheOutputs(taskInst, changed, map[string]interface{}{"name": "other"})
+	_, cached = inst.cachedOutputs(taskInst, hash)
+	assert.False(t, cached)
+}
+
+func TestLogID(t *testing.T) {
+	inst, err := NewIndependentInstance("8f14e45f-ceea-467f-a0e6-8d8c5a3f2b1c", "", getDef(), nil, log.RootLogger())
+	assert.Nil(t, err)
+	assert.Equal(t, inst.ID(), inst.LogID())
+
+	ApplyExecOptions(inst, &ExecOptions{LogID: "order-42"})
+	assert.Equal(t, "order-42", inst.LogID())
+	assert.Equal(t, "8f14e45f-ceea-467f-a0e6-8d8c5a3f2b1c", inst.ID())
+}
+
+func TestAttributeWatcher(t *testing.T) {
+	inst, err := NewIndependentInstance("test", "", getDef(), nil, log.RootLogger())
+	assert.Nil(t, err)
+
+	var changes []string
+	ApplyExecOptions(inst, &ExecOptions{AttributeWatcher: AttributeWatcherFunc(func(instanceID string, name string, value interface{}) {
+		changes = append(changes, fmt.Sprintf("%s:%s=%v", instanceID, name, value))
+	})})
+
+	// attributes set before the first step aren't notified
+	_ = inst.SetValue("count", 1)
+	inst.stepID = 1
+	_ = inst.SetValue("count", 2)
+
+	assert.Equal(t, []string{"test:count=2"}, changes)
+}
+
+func TestTraceSampled(t *testing.T) {
+	inst, err := NewIndependentInstance("test", "", getDef(), nil, log.RootLogger())
+	assert.Nil(t, err)
+
+	// tracing isn't enabled
+	assert.False(t, inst.traced())
+
+	inst.SetTraceSampled(false)
+	assert.True(t, inst.traceSampledOut)
+	assert.False(t, inst.traced())
+
+	inst.SetTraceSampled(true)
+	assert.False(t, inst.traceSampledOut)
+}
+
+func TestExecutionTraceCapped(t *testing.T) {
+	SetMaxExecTraceSteps(2)
+	defer SetMaxExecTraceSteps(0)
+
+	inst, err := NewIndependentInstance("test", "", getDef(), nil, log.RootLogger())
+	assert.Nil(t, err)
+	taskInst := &TaskInst{flowInst: inst.Instance, task: inst.flowDef.GetTask("LogStart"), taskID: "LogStart"}
+
+	for i := 1; i <= 3; i++ {
+		inst.stepID = i
+		inst.startStepRecord(taskInst)
+		inst.finishStepRecord(taskInst)
+	}
+	trace := inst.ExecutionTrace()
+	assert.Len(t, trace, 2)
+	assert.Equal(t, 2, trace[0].StepID)
+	assert.Equal(t, 3, trace[1].StepID)
+
+	SetMaxExecTraceSteps(-1)
+	inst.startStepRecord(taskInst)
+	inst.finishStepRecord(taskInst)
+	assert.Len(t, inst.ExecutionTrace(), 2)
+}
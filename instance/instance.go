@@ -1,6 +1,8 @@
 package instance
 
 import (
+	"context"
+	"fmt"
 	"strconv"
 
 	"github.com/project-flogo/core/action"
@@ -133,6 +135,18 @@ func (inst *Instance) Reply(replyData map[string]interface{}, err error) {
 
 func (inst *Instance) Return(returnData map[string]interface{}, err error) {
 	inst.forceCompletion = true
+
+	if errResult, ok := returnData[ErrorResultKey]; ok && errResult != nil && err == nil {
+		// the return task set a structured error
+		delete(returnData, ErrorResultKey)
+		errWithCode, convErr := toErrorWithCode(errResult)
+		if convErr != nil {
+			err = convErr
+		} else {
+			err = errWithCode
+		}
+	}
+
 	inst.returnData = returnData
 	inst.returnError = err
 }
@@ -146,6 +160,12 @@ func (inst *Instance) GetError() error {
 }
 
 func (inst *Instance) GetReturnData() (map[string]interface{}, error) {
+	return inst.GetReturnDataContext(context.Background())
+}
+
+// GetReturnDataContext is like GetReturnData, but stops building the return data and returns
+// the context's error if the context is done
+func (inst *Instance) GetReturnDataContext(ctx context.Context) (map[string]interface{}, error) {
 
 	if inst.returnData == nil {
 
@@ -154,16 +174,21 @@ func (inst *Instance) GetReturnData() (map[string]interface{}, error) {
 
 		if md != nil && md.Output != nil {
 
-			inst.returnData = make(map[string]interface{})
+			returnData := make(map[string]interface{})
 			for name := range md.Output {
+				if err := ctx.Err(); err != nil {
+					return nil, fmt.Errorf("unable to get return data of flow instance [%s]: %s", inst.ID(), err.Error())
+				}
+
 				piAttr, exists := inst.attrs[name]
 				if exists {
-					inst.returnData[name] = piAttr
+					returnData[name] = piAttr
 				}
 				if md.Output[name].Value() != nil {
-					inst.returnData[name] = md.Output[name].Value()
+					returnData[name] = md.Output[name].Value()
 				}
 			}
+			inst.returnData = returnData
 		}
 	}
 
@@ -209,6 +234,10 @@ func (inst *Instance) Logger() log.Logger {
 
 func (inst *Instance) GetValue(name string) (value interface{}, exists bool) {
 
+	if isFlag(name) {
+		return inst.master.featureFlag(name[len(flagsPrefix):])
+	}
+
 	if inst.attrs != nil {
 		attr, found := inst.attrs[name]
 
@@ -231,6 +260,7 @@ func (inst *Instance) SetValue(name string, value interface{}) error {
 	//if inst.master.trackingChanges {
 	inst.master.changeTracker.AttrChange(inst.subflowId, name, value)
 	//}
+	inst.master.notifyAttrChange(name, value)
 
 	return nil
 }
@@ -246,3 +276,12 @@ func (inst *Instance) UpdateAttrs(attrs map[string]interface{}) {
 		}
 	}
 }
+
+// Attributes returns a copy of the current attributes of the Flow Instance
+func (inst *Instance) Attributes() map[string]interface{} {
+	attrs := make(map[string]interface{}, len(inst.attrs))
+	for name, value := range inst.attrs {
+		attrs[name] = value
+	}
+	return attrs
+}
@@ -4,6 +4,7 @@ import (
 	"github.com/project-flogo/flow/model"
 	"github.com/project-flogo/flow/state"
 	"github.com/project-flogo/flow/state/change"
+	flowsupport "github.com/project-flogo/flow/support"
 	"github.com/project-flogo/flow/util"
 )
 
@@ -148,7 +149,7 @@ func (sct *SimpleChangeTracker) AttrChange(subflowId int, name string, value int
 		fc.Attrs = make(map[string]interface{})
 	}
 
-	fc.Attrs[name] = util.DeepCopy(value)
+	fc.Attrs[name] = util.DeepCopy(flowsupport.RecordableValue(value))
 }
 
 func (sct *SimpleChangeTracker) FlowCreated(flow *IndependentInstance) {
@@ -2,6 +2,9 @@ package instance
 
 import (
 	"encoding/json"
+	"errors"
+	"time"
+
 	"github.com/project-flogo/core/support"
 	"github.com/project-flogo/flow/model"
 )
@@ -18,6 +21,15 @@ type serIndependentInstance struct {
 	TaskInsts []*TaskInst            `json:"tasks"`
 	LinkInsts []*LinkInst            `json:"links"`
 	SubFlows  []*Instance            `json:"subFlows,omitempty"`
+	Inputs    map[string]interface{} `json:"inputs,omitempty"`
+	// SuspendedAt is set when the instance is suspended
+	SuspendedAt *time.Time `json:"suspendedAt,omitempty"`
+	// TaskStates are the statuses of the tasks of the flow (see TaskStates)
+	TaskStates map[string]TaskStatus `json:"taskStates,omitempty"`
+	// PausedTask and PausedError are the ID and error of the failed task the instance is paused on
+	// (see ExecOptions.PauseOnError)
+	PausedTask  string `json:"pausedTask,omitempty"`
+	PausedError string `json:"pausedError,omitempty"`
 }
 
 // MarshalJSON overrides the default MarshalJSON for FlowInstance
@@ -49,16 +61,27 @@ func (inst *IndependentInstance) MarshalJSON() ([]byte, error) {
 		sfs = append(sfs, value)
 	}
 
-	return json.Marshal(&serIndependentInstance{
-		ID:        inst.id,
-		Status:    inst.status,
-		Attrs:     attrs,
-		FlowURI:   inst.flowURI,
-		WorkQueue: queue,
-		TaskInsts: tis,
-		LinkInsts: lis,
-		SubFlows:  sfs,
-	})
+	ser := &serIndependentInstance{
+		ID:         inst.id,
+		Status:     inst.status,
+		Attrs:      attrs,
+		FlowURI:    inst.flowURI,
+		WorkQueue:  queue,
+		TaskInsts:  tis,
+		LinkInsts:  lis,
+		SubFlows:   sfs,
+		Inputs:     inst.inputs,
+		TaskStates: inst.taskStates,
+	}
+	if !inst.suspendedAt.IsZero() {
+		ser.SuspendedAt = &inst.suspendedAt
+	}
+	if inst.pausedTask != nil {
+		ser.PausedTask = inst.pausedTask.taskID
+		ser.PausedError = inst.pausedError.Error()
+	}
+
+	return json.Marshal(ser)
 }
 
 // UnmarshalJSON overrides the default UnmarshalJSON for FlowInstance
@@ -73,6 +96,11 @@ func (inst *IndependentInstance) UnmarshalJSON(d []byte) error {
 	inst.id = ser.ID
 	inst.status = ser.Status
 	inst.flowURI = ser.FlowURI
+	inst.inputs = ser.Inputs
+	inst.taskStates = ser.TaskStates
+	if ser.SuspendedAt != nil {
+		inst.suspendedAt = *ser.SuspendedAt
+	}
 
 	inst.attrs = make(map[string]interface{}, len(ser.Attrs))
 
@@ -88,6 +116,17 @@ func (inst *IndependentInstance) UnmarshalJSON(d []byte) error {
 		inst.taskInsts[taskInst.taskID] = taskInst
 	}
 
+	if ser.PausedTask != "" {
+		// the failed task is kept by the instance, it is initialized when the instance is restarted
+		pausedTask, exists := inst.taskInsts[ser.PausedTask]
+		if !exists {
+			pausedTask = &TaskInst{taskID: ser.PausedTask, status: model.TaskStatusFailed}
+			inst.taskInsts[ser.PausedTask] = pausedTask
+		}
+		inst.pausedTask = pausedTask
+		inst.pausedError = errors.New(ser.PausedError)
+	}
+
 	inst.linkInsts = make(map[int]*LinkInst, len(ser.LinkInsts))
 
 	for _, linkInst := range ser.LinkInsts {
@@ -0,0 +1,28 @@
+package instance
+
+// LinkEvalObserver is notified of every link condition evaluated by a flow, ex. to debug why a
+// branch was or wasn't taken
+type LinkEvalObserver interface {
+	OnLinkEval(fromTask, toTask string, result bool, expr string)
+}
+
+// LinkEvalOverride can force the result of link conditions, ex. to test a specific branch.  The
+// condition is evaluated as usual when ok is false.
+type LinkEvalOverride interface {
+	OverrideLink(fromTask, toTask string, expr string) (result bool, ok bool)
+}
+
+var (
+	linkEvalObserver LinkEvalObserver
+	linkEvalOverride LinkEvalOverride
+)
+
+// SetLinkEvalObserver sets the observer notified of link condition evaluations, nil removes it
+func SetLinkEvalObserver(observer LinkEvalObserver) {
+	linkEvalObserver = observer
+}
+
+// SetLinkEvalOverride sets the override of link condition results, nil removes it
+func SetLinkEvalOverride(override LinkEvalOverride) {
+	linkEvalOverride = override
+}
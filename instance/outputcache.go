@@ -0,0 +1,61 @@
+package instance
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"strconv"
+)
+
+// cachedTaskOutputs are the outputs of the last execution of a task whose outputs are cached,
+// with the hash of the inputs they were produced from
+type cachedTaskOutputs struct {
+	inputsHash string
+	outputs    map[string]interface{}
+}
+
+// taskInputsHash hashes the inputs of the task, false is returned if they can't be hashed
+func taskInputsHash(inputs map[string]interface{}) (string, bool) {
+	// maps are marshalled with sorted keys, so identical inputs have the same hash
+	data, err := json.Marshal(inputs)
+	if err != nil {
+		return "", false
+	}
+
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), true
+}
+
+// taskOutputsKey identifies the task within the instance, the same task can be executed by
+// several subflows
+func taskOutputsKey(taskInst *TaskInst) string {
+	return strconv.Itoa(taskInst.flowInst.subflowId) + ":" + taskInst.taskID
+}
+
+// cachedOutputs returns a copy of the cached outputs of the task if they were produced from
+// inputs with the same hash
+func (inst *IndependentInstance) cachedOutputs(taskInst *TaskInst, inputsHash string) (map[string]interface{}, bool) {
+	cached, ok := inst.taskOutputs[taskOutputsKey(taskInst)]
+	if !ok || cached.inputsHash != inputsHash {
+		return nil, false
+	}
+
+	outputs := make(map[string]interface{}, len(cached.outputs))
+	for name, value := range cached.outputs {
+		outputs[name] = value
+	}
+	return outputs, true
+}
+
+// cacheOutputs caches the outputs of the task, replacing the outputs cached for different inputs
+func (inst *IndependentInstance) cacheOutputs(taskInst *TaskInst, inputsHash string, outputs map[string]interface{}) {
+	if inst.taskOutputs == nil {
+		inst.taskOutputs = make(map[string]*cachedTaskOutputs)
+	}
+
+	cached := &cachedTaskOutputs{inputsHash: inputsHash, outputs: make(map[string]interface{}, len(outputs))}
+	for name, value := range outputs {
+		cached.outputs[name] = value
+	}
+	inst.taskOutputs[taskOutputsKey(taskInst)] = cached
+}
@@ -0,0 +1,43 @@
+package instance
+
+import (
+	"sync"
+
+	"github.com/project-flogo/core/activity"
+)
+
+// ActivityFunc is evaluated in place of an activity, it follows the same contract as activity.Activity.Eval
+type ActivityFunc func(ctx activity.Context) (done bool, err error)
+
+var (
+	overridesMu       sync.RWMutex
+	activityOverrides = make(map[string]ActivityFunc)
+)
+
+// RegisterActivityOverride registers a function that is evaluated instead of the activity with the
+// specified ref by all flow instances.  To limit an override to a single instance, use
+// ExecOptions.ActivityOverrides.
+func RegisterActivityOverride(ref string, fn ActivityFunc) {
+	overridesMu.Lock()
+	activityOverrides[ref] = fn
+	overridesMu.Unlock()
+}
+
+// UnregisterActivityOverride removes the override for the activity with the specified ref
+func UnregisterActivityOverride(ref string) {
+	overridesMu.Lock()
+	delete(activityOverrides, ref)
+	overridesMu.Unlock()
+}
+
+// getActivityOverride returns the override for the specified activity ref, instance overrides
+// take precedence over those registered globally
+func (inst *IndependentInstance) getActivityOverride(ref string) ActivityFunc {
+	if fn, ok := inst.activityOverrides[ref]; ok {
+		return fn
+	}
+
+	overridesMu.RLock()
+	defer overridesMu.RUnlock()
+	return activityOverrides[ref]
+}
@@ -0,0 +1,39 @@
+package instance
+
+import (
+	"github.com/project-flogo/flow/model"
+)
+
+// pauseOnTaskError suspends the instance, using its ID as the token, instead of failing it when a
+// task of the main flow fails and the error isn't handled.  It returns false if the instance isn't
+// paused on errors (see ExecOptions.PauseOnError).
+func (inst *IndependentInstance) pauseOnTaskError(taskInst *TaskInst, err error) bool {
+	if !inst.pauseOnError || taskInst.flowInst != inst.Instance {
+		return false
+	}
+
+	inst.logger.Warnf("Task [%s] failed, pausing instance [%s] for recovery: %v", taskInst.taskID, inst.id, err)
+	inst.pausedTask = taskInst
+	inst.pausedError = err
+	inst.Suspend(inst.id)
+	return true
+}
+
+// PausedError returns the error of the failed task the instance is paused on, nil if the instance
+// isn't paused on an error (see ExecOptions.PauseOnError)
+func (inst *IndependentInstance) PausedError() error {
+	return inst.pausedError
+}
+
+// RetryPausedTask schedules the failed task the instance is paused on to be evaluated again when
+// the instance is resumed, it does nothing if the instance isn't paused on an error
+func (inst *IndependentInstance) RetryPausedTask() {
+	if inst.pausedTask == nil {
+		return
+	}
+
+	inst.pausedTask.SetStatus(model.TaskStatusReady)
+	inst.scheduleEval(inst.pausedTask)
+	inst.pausedTask = nil
+	inst.pausedError = nil
+}
@@ -1,14 +1,35 @@
 package instance
 
 import (
+	"fmt"
+
+	"github.com/project-flogo/flow/model"
 	"github.com/project-flogo/flow/state"
 	"time"
 )
 
+var recordingSampler state.Sampler
+var failOnRecorderError bool
+
+// SetRecordingSampler sets the Sampler used to decide which steps of instances are recorded,
+// nil records every step
+func SetRecordingSampler(sampler state.Sampler) {
+	recordingSampler = sampler
+}
+
+// SetFailOnRecorderError sets whether RecordState returns the errors of the state recorder, which
+// fails the instance, instead of logging them
+func SetFailOnRecorderError(fail bool) {
+	failOnRecorderError = fail
+}
+
 type stateInstanceRecorder struct {
 	mod              state.RecordingMode
 	externalRecorder state.Recorder
 	rerun            bool
+
+	stepCount  int
+	lastStatus model.FlowStatus
 }
 
 func NewStateInstanceRecorder(recorder state.Recorder, mod state.RecordingMode, rerunstate bool) *stateInstanceRecorder {
@@ -20,22 +41,57 @@ func NewStateInstanceRecorder(recorder state.Recorder, mod state.RecordingMode,
 }
 
 func (inst *IndependentInstance) RecordState(strtTime time.Time) error {
+	if err := inst.recordCheckpoint(); err != nil {
+		if failOnRecorderError {
+			return fmt.Errorf("unable to record checkpoint for instance [%s]: %s", inst.id, err.Error())
+		}
+		inst.logger.Errorf("unable to record checkpoint for instance [%s]: %v", inst.id, err)
+	}
+
+	if !inst.sampleStep() {
+		return nil
+	}
+
 	if state.RecordSnapshot(inst.instRecorder.mod) {
 		err := inst.instRecorder.externalRecorder.RecordSnapshot(inst.Snapshot())
 		if err != nil {
-			inst.logger.Warnf("unable to record snapshot: %v", err)
+			if failOnRecorderError {
+				return fmt.Errorf("unable to record snapshot for instance [%s]: %s", inst.id, err.Error())
+			}
+			inst.logger.Errorf("unable to record snapshot for instance [%s]: %v", inst.id, err)
 		}
 	}
 
 	if state.RecordSteps(inst.instRecorder.mod) {
 		currStep := inst.CurrentStep(true)
+		// the tracker only counts the recorded steps, use the ID of the instance's step so the
+		// recorded steps keep their IDs when steps are sampled out
+		currStep.Id = inst.stepID
 		currStep.StartTime = strtTime
 		currStep.EndTime = time.Now().UTC()
 		currStep.Rerun = inst.instRecorder.rerun
 		err := inst.instRecorder.externalRecorder.RecordStep(currStep)
 		if err != nil {
-			inst.logger.Warnf("unable to record step: %v", err)
+			if failOnRecorderError {
+				return fmt.Errorf("unable to record step for instance [%s]: %s", inst.id, err.Error())
+			}
+			inst.logger.Errorf("unable to record step for instance [%s]: %v", inst.id, err)
 		}
 	}
 	return nil
 }
+
+// sampleStep checks if the current step should be recorded, the first and last steps are always recorded
+func (inst *IndependentInstance) sampleStep() bool {
+	r := inst.instRecorder
+	r.stepCount++
+
+	status := inst.Status()
+	statusChanged := status != r.lastStatus
+	r.lastStatus = status
+
+	if recordingSampler == nil || r.stepCount == 1 || status >= model.FlowStatusCompleted {
+		return true
+	}
+	return recordingSampler.Sample(r.stepCount, statusChanged)
+}
@@ -0,0 +1,77 @@
+package instance
+
+import (
+	"fmt"
+
+	"github.com/project-flogo/flow/definition"
+)
+
+// UpdateDefinition replaces the definition of the instance, which is used for the remaining steps.
+// The tasks the instance has already executed, or is executing, must be unchanged in the new
+// definition.  They are known from the task states of the instance, which are serialized, so a
+// restored instance is validated as well.
+func (inst *IndependentInstance) UpdateDefinition(def *definition.Definition) error {
+	if def == nil {
+		return fmt.Errorf("unable to update definition of instance [%s], definition not provided", inst.id)
+	}
+
+	for taskID, status := range inst.taskStates {
+		if status == TaskStatusNotStarted {
+			continue
+		}
+		if err := checkTaskUnchanged(inst.flowDef, def, taskID); err != nil {
+			return err
+		}
+	}
+	for _, r := range inst.execTrace {
+		if r.SubflowID != 0 {
+			continue
+		}
+		if err := checkTaskUnchanged(inst.flowDef, def, r.TaskID); err != nil {
+			return err
+		}
+	}
+	for taskID := range inst.taskInsts {
+		if err := checkTaskUnchanged(inst.flowDef, def, taskID); err != nil {
+			return err
+		}
+	}
+	for linkID := range inst.linkInsts {
+		if def.GetLink(linkID) == nil {
+			return fmt.Errorf("link [%d] of instance [%s] not found in new definition", linkID, inst.id)
+		}
+	}
+
+	flowModel, err := getFlowModel(def)
+	if err != nil {
+		return err
+	}
+
+	inst.flowDef = def
+	inst.flowModel = flowModel
+	inst.init(inst.Instance)
+
+	return nil
+}
+
+func checkTaskUnchanged(oldDef, newDef *definition.Definition, taskID string) error {
+	oldTask := findTask(oldDef, taskID)
+	newTask := findTask(newDef, taskID)
+	if newTask == nil {
+		return fmt.Errorf("executed task [%s] not found in new definition", taskID)
+	}
+	if !oldTask.Equal(newTask) {
+		return fmt.Errorf("executed task [%s] differs in new definition", taskID)
+	}
+	return nil
+}
+
+func findTask(def *definition.Definition, taskID string) *definition.Task {
+	if task := def.GetTask(taskID); task != nil {
+		return task
+	}
+	if def.GetErrorHandler() != nil {
+		return def.GetErrorHandler().GetTask(taskID)
+	}
+	return nil
+}
@@ -0,0 +1,87 @@
+package instance
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/project-flogo/flow/model"
+)
+
+// SequenceDiagram returns a Mermaid sequence diagram of the tasks executed by the instance, built
+// from its execution trace.  Each task is a participant, a task is entered by the task whose links
+// led to it, or by the flow for the first tasks.  A fork is noted on the task it happens at and
+// repeated executions of a task, ex. in a loop, are numbered.
+func (inst *IndependentInstance) SequenceDiagram() string {
+	type participantKey struct {
+		subflowID int
+		taskID    string
+	}
+
+	var b strings.Builder
+	b.WriteString("sequenceDiagram\n")
+	fmt.Fprintf(&b, "    participant flow as %s\n", diagramText(inst.Name()))
+
+	participants := make(map[participantKey]string)
+	participant := func(subflowID int, r *StepRecord) string {
+		key := participantKey{subflowID: subflowID, taskID: r.TaskID}
+		if id, ok := participants[key]; ok {
+			return id
+		}
+
+		id := fmt.Sprintf("t%d", len(participants)+1)
+		participants[key] = id
+
+		name := r.TaskName
+		if name == "" {
+			name = r.TaskID
+		}
+		if subflowID > 0 {
+			name = fmt.Sprintf("%s (subflow %d)", name, subflowID)
+		}
+		fmt.Fprintf(&b, "    participant %s as %s\n", id, diagramText(name))
+		return id
+	}
+
+	// the task whose links entered each task, keyed by subflow and task
+	enteredBy := make(map[participantKey]string)
+	runs := make(map[participantKey]int)
+
+	for _, r := range inst.execTrace {
+		key := participantKey{subflowID: r.SubflowID, taskID: r.TaskID}
+		to := participant(r.SubflowID, r)
+
+		from, ok := enteredBy[key]
+		if !ok {
+			from = "flow"
+		}
+		delete(enteredBy, key)
+
+		runs[key]++
+		label := fmt.Sprintf("step %d", r.StepID)
+		if runs[key] > 1 {
+			label = fmt.Sprintf("%s (run %d)", label, runs[key])
+		}
+		fmt.Fprintf(&b, "    %s->>%s: %s\n", from, to, label)
+
+		switch r.Status {
+		case model.TaskStatusFailed:
+			fmt.Fprintf(&b, "    Note over %s: failed\n", to)
+		case model.TaskStatusSkipped:
+			fmt.Fprintf(&b, "    Note over %s: skipped\n", to)
+		}
+
+		if len(r.NextTasks) > 1 {
+			fmt.Fprintf(&b, "    Note over %s: fork to %s\n", to, diagramText(strings.Join(r.NextTasks, ", ")))
+		}
+		for _, next := range r.NextTasks {
+			enteredBy[participantKey{subflowID: r.SubflowID, taskID: next}] = to
+		}
+	}
+
+	return b.String()
+}
+
+// diagramText makes the text safe to use in a Mermaid diagram
+func diagramText(text string) string {
+	return strings.NewReplacer("\n", " ", "\r", " ", ";", ",", "#", "").Replace(text)
+}
@@ -0,0 +1,45 @@
+package instance
+
+import "time"
+
+// SuspendContext is implemented by the activity.Context passed to activities executed by a flow,
+// it allows an activity to suspend the instance once the current step completes.  The instance
+// can then be resumed using the correlation token.
+type SuspendContext interface {
+	Suspend(token string)
+}
+
+// Suspend implements SuspendContext.Suspend
+func (ti *TaskInst) Suspend(token string) {
+	ti.logger.Debugf("Task[%s] - Suspending instance with token: %s", ti.taskID, token)
+	ti.flowInst.master.Suspend(token)
+}
+
+func (l *LegacyCtx) Suspend(token string) {
+	l.task.Suspend(token)
+}
+
+// Suspend suspends the instance once the current step completes, it can then be resumed using
+// the correlation token
+func (inst *IndependentInstance) Suspend(token string) {
+	inst.suspendToken = token
+	inst.suspendedAt = time.Now().UTC()
+}
+
+// SuspendToken returns the correlation token the instance was suspended with, an empty
+// string indicates that the instance isn't suspended
+func (inst *IndependentInstance) SuspendToken() string {
+	return inst.suspendToken
+}
+
+// ClearSuspend clears the suspended state of the instance so that it can continue executing
+func (inst *IndependentInstance) ClearSuspend() {
+	inst.suspendToken = ""
+	inst.suspendedAt = time.Time{}
+}
+
+// SuspendedAt returns when the instance was suspended, it is part of the instance's serialized
+// state so the age of a persisted suspended instance can be checked when it's resumed
+func (inst *IndependentInstance) SuspendedAt() time.Time {
+	return inst.suspendedAt
+}
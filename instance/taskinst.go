@@ -13,6 +13,7 @@ import (
 	"github.com/project-flogo/core/support/trace"
 	"github.com/project-flogo/flow/definition"
 	"github.com/project-flogo/flow/model"
+	"github.com/project-flogo/flow/support/store"
 )
 
 func NewTaskInst(flowInst *Instance, task *definition.Task) *TaskInst {
@@ -72,6 +73,9 @@ type TaskInst struct {
 	returnError  error
 	traceContext trace.TracingContext
 
+	// skipped indicates that the task's skipIf expression was true or its feature flag was off
+	skipped bool
+
 	//needed for serialization
 	taskID string
 }
@@ -148,6 +152,11 @@ func (ti *TaskInst) GetTracingContext() trace.TracingContext {
 	return ti.traceContext
 }
 
+// StepNumber implements StepContext.StepNumber
+func (ti *TaskInst) StepNumber() int {
+	return ti.flowInst.master.stepID
+}
+
 func (ti *TaskInst) GetSharedTempData() map[string]interface{} {
 	//todo implement
 	return nil
@@ -169,6 +178,7 @@ func (ti *TaskInst) Status() model.TaskStatus {
 func (ti *TaskInst) SetStatus(status model.TaskStatus) {
 	ti.status = status
 	ti.flowInst.master.changeTracker.TaskUpdated(ti)
+	ti.flowInst.master.trackTaskStatus(ti)
 	postTaskEvent(ti)
 }
 
@@ -272,17 +282,34 @@ func (ti *TaskInst) EvalLink(link *definition.Link) (result bool, err error) {
 	}()
 
 	if expr := link.Expr(); expr != nil {
+		if linkEvalOverride != nil {
+			if result, ok := linkEvalOverride.OverrideLink(link.FromTask().ID(), link.ToTask().ID(), link.Value()); ok {
+				ti.notifyLinkEval(link, result)
+				return result, nil
+			}
+		}
+
 		result, err := expr.Eval(ti.flowInst)
 		if err != nil {
 			return false, err
 		}
 
-		return coerce.ToBool(result)
+		follow, err := coerce.ToBool(result)
+		if err == nil {
+			ti.notifyLinkEval(link, follow)
+		}
+		return follow, err
 	}
 
 	return true, nil
 }
 
+func (ti *TaskInst) notifyLinkEval(link *definition.Link, result bool) {
+	if linkEvalObserver != nil {
+		linkEvalObserver.OnLinkEval(link.FromTask().ID(), link.ToTask().ID(), result, link.Value())
+	}
+}
+
 // HasActivity implements activity.ActivityContext.HasActivity method
 func (ti *TaskInst) HasActivity() bool {
 	return ti.task.ActivityConfig().Activity != nil
@@ -316,8 +343,25 @@ func (ti *TaskInst) EvalActivity() (done bool, evalErr error) {
 
 	eval := true
 
+	ti.skipped = false
+	if flag := ti.task.FeatureFlag(); flag != "" && !ti.flowInst.master.featureFlagOn(flag) {
+		ti.logger.Debugf("Task[%s] - Skipping activity, feature flag '%s' is off", ti.taskID, flag)
+		ti.skipped = true
+		return true, nil
+	}
+	if skipIf := ti.task.SkipIf(); skipIf != nil {
+		result, err := skipIf.Eval(ti.flowInst)
+		if err != nil {
+			return false, NewActivityEvalError(ti.task.Name(), "skipIf", err.Error())
+		}
+		if ti.skipped, _ = coerce.ToBool(result); ti.skipped {
+			ti.logger.Debugf("Task[%s] - Skipping activity, skipIf expression is true", ti.taskID)
+			return true, nil
+		}
+	}
+
 	// Start Trace
-	if trace.Enabled() {
+	if ti.flowInst.master.traced() {
 		ti.traceContext, _ = trace.GetTracer().StartTrace(ti.SpanConfig(), ti.flowInst.tracingCtx)
 	}
 
@@ -357,7 +401,26 @@ func (ti *TaskInst) EvalActivity() (done bool, evalErr error) {
 			ctx = &LegacyCtx{task: ti}
 		}
 
-		done, evalErr = actCfg.Activity.Eval(ctx)
+		// the outputs of a task executed again with identical inputs are reused
+		var inputsHash string
+		var cachedOutputs map[string]interface{}
+		cacheOutputs, cached := false, false
+		if ti.task.CacheOutputs() {
+			if inputsHash, cacheOutputs = taskInputsHash(ti.inputs); cacheOutputs {
+				cachedOutputs, cached = ti.flowInst.master.cachedOutputs(ti, inputsHash)
+			}
+		}
+
+		if cached {
+			ti.logger.Debugf("Task[%s] - Using cached outputs, inputs are unchanged", ti.taskID)
+			ti.outputs = cachedOutputs
+			done = true
+		} else if override := ti.flowInst.master.getActivityOverride(actCfg.Ref()); override != nil {
+			ti.logger.Debugf("Evaluating override for activity [%s]", actCfg.Ref())
+			done, evalErr = override(ctx)
+		} else {
+			done, evalErr = actCfg.Activity.Eval(ctx)
+		}
 
 		if evalErr != nil {
 			e, ok := evalErr.(*activity.Error)
@@ -368,6 +431,10 @@ func (ti *TaskInst) EvalActivity() (done bool, evalErr error) {
 			return false, evalErr
 		}
 
+		if cacheOutputs && done {
+			ti.flowInst.master.cacheOutputs(ti, inputsHash, ti.outputs)
+		}
+
 	} else {
 		done = true
 	}
@@ -584,6 +651,24 @@ func NewErrorObj(taskId string, msg string) map[string]interface{} {
 	return map[string]interface{}{"activity": taskId, "message": msg, "type": "unknown", "code": "", "data": nil}
 }
 
+// StepContext is implemented by the activity.Context passed to activities executed by a flow,
+// it provides the number of the step executing the activity.  This is the same step number
+// used when recording the instance's state.
+type StepContext interface {
+	StepNumber() int
+}
+
+// SharedStoreContext is implemented by the activity.Context passed to activities executed by a
+// flow, it provides the store of values shared across flow instances
+type SharedStoreContext interface {
+	SharedStore() store.SharedStore
+}
+
+// SharedStore implements SharedStoreContext.SharedStore
+func (ti *TaskInst) SharedStore() store.SharedStore {
+	return store.GetSharedStore()
+}
+
 //DEPRECATED
 type LegacyCtx struct {
 	task *TaskInst
@@ -602,6 +687,10 @@ func (l *LegacyCtx) GetOutput(name string) interface{} {
 	return nil
 }
 
+func (l *LegacyCtx) Task() *definition.Task {
+	return l.task.Task()
+}
+
 func (l *LegacyCtx) ActivityHost() activity.Host {
 	return l.task.ActivityHost()
 }
@@ -630,6 +719,14 @@ func (l *LegacyCtx) GetTracingContext() trace.TracingContext {
 	return l.task.traceContext
 }
 
+func (l *LegacyCtx) SharedStore() store.SharedStore {
+	return l.task.SharedStore()
+}
+
+func (l *LegacyCtx) StepNumber() int {
+	return l.task.StepNumber()
+}
+
 func (l *LegacyCtx) GetSharedTempData() map[string]interface{} {
 	return l.task.GetSharedTempData()
 }
@@ -0,0 +1,64 @@
+package instance
+
+import (
+	"github.com/project-flogo/flow/model"
+)
+
+// TaskStatus is the status of a task of an instance, as reported by TaskStates
+type TaskStatus string
+
+const (
+	// TaskStatusNotStarted indicates the task hasn't been entered yet
+	TaskStatusNotStarted TaskStatus = "not-started"
+	// TaskStatusRunning indicates the task has been entered and hasn't finished, ex. it is queued
+	// to be executed or waiting for its activity to complete
+	TaskStatusRunning TaskStatus = "running"
+	// TaskStatusDone indicates the task completed
+	TaskStatusDone TaskStatus = "done"
+	// TaskStatusSkipped indicates the task was skipped
+	TaskStatusSkipped TaskStatus = "skipped"
+	// TaskStatusFailed indicates the task failed
+	TaskStatusFailed TaskStatus = "failed"
+)
+
+// TaskStates returns the status of every task in the definition of the flow, tasks of subflows
+// aren't included.  A task that is executed again, ex. in a loop, reports its latest status.
+func (inst *IndependentInstance) TaskStates() map[string]TaskStatus {
+	states := make(map[string]TaskStatus)
+	if inst.flowDef != nil {
+		for _, task := range inst.flowDef.Tasks() {
+			states[task.ID()] = TaskStatusNotStarted
+		}
+	}
+	for taskID, status := range inst.taskStates {
+		states[taskID] = status
+	}
+	return states
+}
+
+// trackTaskStatus notes the status of the task of the flow, it is called when the status of one of
+// its task instances changes
+func (inst *IndependentInstance) trackTaskStatus(ti *TaskInst) {
+	if ti.flowInst.subflowId != 0 {
+		return
+	}
+	if inst.taskStates == nil {
+		inst.taskStates = make(map[string]TaskStatus)
+	}
+	inst.taskStates[ti.taskID] = toTaskStatus(ti.status)
+}
+
+func toTaskStatus(status model.TaskStatus) TaskStatus {
+	switch status {
+	case model.TaskStatusNotStarted:
+		return TaskStatusNotStarted
+	case model.TaskStatusDone:
+		return TaskStatusDone
+	case model.TaskStatusSkipped:
+		return TaskStatusSkipped
+	case model.TaskStatusFailed:
+		return TaskStatusFailed
+	default:
+		return TaskStatusRunning
+	}
+}
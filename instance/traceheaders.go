@@ -0,0 +1,39 @@
+package instance
+
+import (
+	"github.com/project-flogo/core/support/trace"
+)
+
+// TraceHeadersContext is implemented by the activity.Context passed to activities executed by a
+// flow, it provides the propagation headers of the activity's trace so they can be injected into
+// outbound requests (ex. HTTP or gRPC calls)
+type TraceHeadersContext interface {
+	TraceHeaders() map[string]string
+}
+
+// TraceHeaders implements TraceHeadersContext.TraceHeaders
+func (ti *TaskInst) TraceHeaders() map[string]string {
+	return traceHeaders(ti.traceContext)
+}
+
+func (l *LegacyCtx) TraceHeaders() map[string]string {
+	return l.task.TraceHeaders()
+}
+
+// TraceHeaders returns the propagation headers of the instance's trace, nil if tracing isn't enabled
+func (inst *Instance) TraceHeaders() map[string]string {
+	return traceHeaders(inst.tracingCtx)
+}
+
+// traceHeaders injects the tracing context into headers, using the trace.TextMap format
+func traceHeaders(tracingCtx trace.TracingContext) map[string]string {
+	if tracingCtx == nil || !trace.Enabled() {
+		return nil
+	}
+
+	headers := make(map[string]string)
+	if err := trace.GetTracer().Inject(tracingCtx, trace.TextMap, headers); err != nil {
+		return nil
+	}
+	return headers
+}
@@ -0,0 +1,378 @@
+package flow
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/project-flogo/flow/instance"
+	"github.com/project-flogo/flow/model"
+)
+
+// InstanceInfo describes a flow instance that is currently being executed by the engine
+type InstanceInfo struct {
+	ID        string            `json:"id"`
+	LogID     string            `json:"logId,omitempty"`
+	FlowURI   string            `json:"flowURI"`
+	FlowName  string            `json:"flowName"`
+	Status    model.FlowStatus  `json:"status"`
+	StepCount int               `json:"stepCount"`
+	Paused    bool              `json:"paused"`
+	StartTime time.Time         `json:"startTime"`
+	Labels    map[string]string `json:"labels,omitempty"`
+	// MemoryBytes is the approximate memory footprint of the instance's attributes, it is
+	// only tracked when instance memory tracking or a memory limit is enabled
+	MemoryBytes int `json:"memoryBytes,omitempty"`
+	// Outputs are the outputs of a completed instance, the values of sensitive fields are redacted
+	Outputs map[string]interface{} `json:"outputs,omitempty"`
+	// Error is the error of a failed instance
+	Error string `json:"error,omitempty"`
+}
+
+type runningInstance struct {
+	mu        sync.Mutex
+	stepMu    sync.Mutex // held by the step goroutine while executing a step
+	inst      *instance.IndependentInstance
+	flowURI   string
+	startTime time.Time
+	stepCount int
+	status    model.FlowStatus
+	labels    map[string]string
+	paused    bool
+	resume    chan struct{}
+	cancelled bool
+	memory    int
+	outputs   map[string]interface{}
+	// checkpoint indicates that the instance should be suspended before its next step
+	checkpoint bool
+	// cancelCtx cancels the context provided to the activities of the instance
+	cancelCtx context.CancelFunc
+}
+
+var (
+	riMu             sync.RWMutex // protects the running and finished instances maps
+	runningInstances = make(map[string]*runningInstance)
+	// finishedInstances are retained for instanceRetention after they finish
+	finishedInstances = make(map[string]*InstanceInfo)
+	instanceRetention time.Duration
+)
+
+// registerInstance adds the instance to the running instances, applying the policy if an instance
+// with the same ID is running
+func registerInstance(inst *instance.IndependentInstance, flowURI string, policy DuplicateIDPolicy) (*runningInstance, error) {
+	ri := &runningInstance{inst: inst, flowURI: flowURI, startTime: time.Now().UTC(), status: inst.Status()}
+
+	riMu.Lock()
+	defer riMu.Unlock()
+
+	if err := checkDuplicateInstance(inst.ID(), policy); err != nil {
+		return nil, err
+	}
+	runningInstances[inst.ID()] = ri
+
+	return ri, nil
+}
+
+// discardInstance removes an instance that wasn't started from the running instances
+func discardInstance(ri *runningInstance) {
+	riMu.Lock()
+	if runningInstances[ri.inst.ID()] == ri {
+		delete(runningInstances, ri.inst.ID())
+	}
+	riMu.Unlock()
+}
+
+// unregisterInstance removes the instance from the running instances, unless it has been replaced
+// by another instance with the same ID.  When instances are retained, its final state is kept
+// for instanceRetention.
+func unregisterInstance(ri *runningInstance) {
+	id := ri.inst.ID()
+
+	var info *InstanceInfo
+	if instanceRetention > 0 {
+		info = ri.info()
+		if err := ri.inst.GetError(); err != nil {
+			info.Error = err.Error()
+		}
+	}
+
+	riMu.Lock()
+	if runningInstances[id] == ri {
+		delete(runningInstances, id)
+	}
+	if info != nil {
+		finishedInstances[id] = info
+	}
+	riMu.Unlock()
+
+	if info != nil {
+		time.AfterFunc(instanceRetention, func() {
+			riMu.Lock()
+			if finishedInstances[id] == info {
+				delete(finishedInstances, id)
+			}
+			riMu.Unlock()
+		})
+	}
+}
+
+func getRunningInstance(id string) *runningInstance {
+	riMu.RLock()
+	defer riMu.RUnlock()
+	return runningInstances[id]
+}
+
+// setStep records the step the instance is about to execute
+func (ri *runningInstance) setStep(stepCount int) {
+	ri.mu.Lock()
+	ri.stepCount = stepCount
+	ri.mu.Unlock()
+}
+
+// setState records the status and labels of the instance, it is called by the step goroutine so
+// that info doesn't read the instance while a step changes it
+func (ri *runningInstance) setState(status model.FlowStatus, labels map[string]string) {
+	ri.mu.Lock()
+	ri.status = status
+	ri.labels = labels
+	ri.mu.Unlock()
+}
+
+// pause blocks the calling step goroutine until the instance is resumed, it returns immediately
+// if the instance was cancelled or a checkpoint was requested
+func (ri *runningInstance) pause() {
+	ri.mu.Lock()
+	if ri.cancelled || ri.checkpoint {
+		ri.mu.Unlock()
+		return
+	}
+	ri.paused = true
+	ri.resume = make(chan struct{})
+	resume := ri.resume
+	ri.mu.Unlock()
+
+	<-resume
+}
+
+// cancel signals the step goroutine to cancel the instance, resuming it if paused, and cancels the
+// context provided to its activities
+func (ri *runningInstance) cancel() {
+	ri.mu.Lock()
+	defer ri.mu.Unlock()
+
+	ri.cancelled = true
+	if ri.cancelCtx != nil {
+		ri.cancelCtx()
+	}
+	if ri.paused {
+		ri.paused = false
+		close(ri.resume)
+	}
+}
+
+// setCancelCtx sets the function cancelling the context provided to the activities of the instance
+func (ri *runningInstance) setCancelCtx(cancelCtx context.CancelFunc) {
+	ri.mu.Lock()
+	ri.cancelCtx = cancelCtx
+	ri.mu.Unlock()
+}
+
+// requestCheckpoint signals the step goroutine to suspend the instance, resuming it if paused
+func (ri *runningInstance) requestCheckpoint() {
+	ri.mu.Lock()
+	defer ri.mu.Unlock()
+
+	ri.checkpoint = true
+	if ri.paused {
+		ri.paused = false
+		close(ri.resume)
+	}
+}
+
+func (ri *runningInstance) checkpointRequested() bool {
+	ri.mu.Lock()
+	defer ri.mu.Unlock()
+	return ri.checkpoint
+}
+
+func (ri *runningInstance) setMemory(memory int) {
+	ri.mu.Lock()
+	ri.memory = memory
+	ri.mu.Unlock()
+}
+
+// setOutputs records the outputs of the completed instance
+func (ri *runningInstance) setOutputs(outputs map[string]interface{}) {
+	ri.mu.Lock()
+	ri.outputs = redact(outputs)
+	ri.mu.Unlock()
+}
+
+func (ri *runningInstance) isCancelled() bool {
+	ri.mu.Lock()
+	defer ri.mu.Unlock()
+	return ri.cancelled
+}
+
+func (ri *runningInstance) info() *InstanceInfo {
+	ri.mu.Lock()
+	defer ri.mu.Unlock()
+
+	return &InstanceInfo{
+		ID:          ri.inst.ID(),
+		LogID:       ri.inst.LogID(),
+		FlowURI:     ri.flowURI,
+		FlowName:    ri.inst.Name(),
+		Status:      ri.status,
+		StepCount:   ri.stepCount,
+		Paused:      ri.paused,
+		StartTime:   ri.startTime,
+		Labels:      ri.labels,
+		MemoryBytes: ri.memory,
+		Outputs:     ri.outputs,
+	}
+}
+
+// GetRunningInstance returns information about the running instance with the specified ID.  An
+// instance that finished within the instance retention window is also returned, with its final
+// status and outputs.
+func GetRunningInstance(id string) (*InstanceInfo, bool) {
+	ri := getRunningInstance(id)
+	if ri == nil {
+		riMu.RLock()
+		info, exists := finishedInstances[id]
+		riMu.RUnlock()
+		return info, exists
+	}
+	return ri.info(), true
+}
+
+// RunningInstances returns information about all running instances, ordered by start time
+func RunningInstances() []*InstanceInfo {
+	riMu.RLock()
+	infos := make([]*InstanceInfo, 0, len(runningInstances))
+	for _, ri := range runningInstances {
+		infos = append(infos, ri.info())
+	}
+	riMu.RUnlock()
+
+	sort.Slice(infos, func(i, j int) bool {
+		return infos[i].StartTime.Before(infos[j].StartTime)
+	})
+
+	return infos
+}
+
+// GetInstanceAttributes returns a copy of the current attributes of the running instance with the
+// specified ID, the values of sensitive fields are redacted.  If the instance is executing a step,
+// the call waits for the step to complete.
+func GetInstanceAttributes(id string) (map[string]interface{}, error) {
+	ri := getRunningInstance(id)
+	if ri == nil {
+		return nil, fmt.Errorf("instance [%s] is not running", id)
+	}
+
+	ri.stepMu.Lock()
+	attrs := ri.inst.Attributes()
+	ri.stepMu.Unlock()
+
+	return redact(attrs), nil
+}
+
+// GetInstanceTaskStates returns the status of every task of the running instance with the
+// specified ID (see IndependentInstance.TaskStates).  If the instance is executing a step, the
+// call waits for the step to complete.
+func GetInstanceTaskStates(id string) (map[string]instance.TaskStatus, error) {
+	ri := getRunningInstance(id)
+	if ri == nil {
+		return nil, fmt.Errorf("instance [%s] is not running", id)
+	}
+
+	ri.stepMu.Lock()
+	states := ri.inst.TaskStates()
+	ri.stepMu.Unlock()
+
+	return states, nil
+}
+
+// ResumeInstance resumes a paused instance
+func ResumeInstance(id string) error {
+	ri := getRunningInstance(id)
+	if ri == nil {
+		return fmt.Errorf("instance [%s] is not running", id)
+	}
+
+	ri.mu.Lock()
+	defer ri.mu.Unlock()
+
+	if !ri.paused {
+		return fmt.Errorf("instance [%s] is not paused", id)
+	}
+
+	ri.paused = false
+	close(ri.resume)
+
+	return nil
+}
+
+// KillInstance cancels the running instance with the specified ID, the instance stops
+// before executing its next step
+func KillInstance(id string) error {
+	ri := getRunningInstance(id)
+	if ri == nil {
+		return fmt.Errorf("instance [%s] is not running", id)
+	}
+
+	ri.cancel()
+	return nil
+}
+
+// KillInstancesByFlow cancels all running instances of the specified flow, returning the
+// number of instances that were cancelled
+func KillInstancesByFlow(flowURI string) (killed int, err error) {
+	if flowURI == "" {
+		return 0, fmt.Errorf("flowURI not specified")
+	}
+
+	riMu.RLock()
+	defer riMu.RUnlock()
+
+	for _, ri := range runningInstances {
+		if ri.flowURI == flowURI {
+			ri.cancel()
+			killed++
+		}
+	}
+
+	return killed, nil
+}
+
+// PauseAll checkpoints and pauses all running instances, ex. for a maintenance window.  Each
+// instance records a snapshot and is suspended before its next step, it can later be resumed
+// with OpResume using its ID as the RunOptions.ResumeToken.  The IDs of all the instances paused
+// this way, including by previous calls, that haven't been resumed are returned.
+func PauseAll() ([]string, error) {
+	var ids []string
+
+	riMu.RLock()
+	for id, ri := range runningInstances {
+		ri.requestCheckpoint()
+		ids = append(ids, id)
+	}
+	riMu.RUnlock()
+
+	suspended, err := suspendedInstances()
+	if err != nil {
+		return nil, err
+	}
+	for token, inst := range suspended {
+		if token == inst.ID() && !containsString(ids, token) {
+			ids = append(ids, token)
+		}
+	}
+
+	sort.Strings(ids)
+	return ids, nil
+}
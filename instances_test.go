@@ -0,0 +1,166 @@
+package flow
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/project-flogo/core/activity"
+	"github.com/project-flogo/flow/definition"
+	"github.com/project-flogo/flow/instance"
+	"github.com/project-flogo/flow/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInstanceNotRunning(t *testing.T) {
+	_, exists := GetRunningInstance("unknown")
+	assert.False(t, exists)
+
+	assert.NotNil(t, ResumeInstance("unknown"))
+	assert.NotNil(t, KillInstance("unknown"))
+
+	_, err := GetInstanceAttributes("unknown")
+	assert.NotNil(t, err)
+
+	_, err = GetInstanceTaskStates("unknown")
+	assert.NotNil(t, err)
+
+	killed, err := KillInstancesByFlow("res://flow:unknown")
+	assert.Nil(t, err)
+	assert.Equal(t, 0, killed)
+
+	_, err = KillInstancesByFlow("")
+	assert.NotNil(t, err)
+}
+
+func TestPauseAllNoInstances(t *testing.T) {
+	ids, err := PauseAll()
+	assert.Nil(t, err)
+	assert.Empty(t, ids)
+}
+
+func TestInstanceRetention(t *testing.T) {
+	inst := newTestInstance(t, "retained")
+
+	instanceRetention = 50 * time.Millisecond
+	defer func() { instanceRetention = 0 }()
+
+	ri, _ := registerInstance(inst, "res://flow:retained", DuplicateIDIgnore)
+	ri.setOutputs(map[string]interface{}{"result": "ok"})
+	unregisterInstance(ri)
+
+	info, exists := GetRunningInstance("retained")
+	assert.True(t, exists)
+	assert.Equal(t, "ok", info.Outputs["result"])
+	assert.Empty(t, RunningInstances())
+
+	time.Sleep(100 * time.Millisecond)
+	_, exists = GetRunningInstance("retained")
+	assert.False(t, exists)
+}
+
+func TestInstanceInfoDuringStep(t *testing.T) {
+	inst := newTestInstance(t, "info",
+		&definition.TaskRep{ID: "a", ActivityCfgRep: &activity.Config{Ref: "selftest-ok"}},
+		&definition.TaskRep{ID: "b", ActivityCfgRep: &activity.Config{Ref: "selftest-ok"}})
+	inst.SetLabels(map[string]string{"tenant": "acme"})
+
+	ri, _ := registerInstance(inst, "res://flow:info", DuplicateIDIgnore)
+	defer unregisterInstance(ri)
+	ri.setState(inst.Status(), inst.Labels())
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		inst.Start(nil)
+		for i := 0; i < 10 && inst.Status() == model.FlowStatusActive; i++ {
+			ri.stepMu.Lock()
+			inst.DoStep()
+			ri.stepMu.Unlock()
+			ri.setState(inst.Status(), inst.Labels())
+		}
+	}()
+
+	for running := true; running; {
+		select {
+		case <-done:
+			running = false
+		default:
+		}
+		info, exists := GetRunningInstance("info")
+		assert.True(t, exists)
+		assert.Equal(t, "acme", info.Labels["tenant"])
+	}
+
+	info, _ := GetRunningInstance("info")
+	assert.Equal(t, model.FlowStatusCompleted, info.Status)
+}
+
+func TestEngineStats(t *testing.T) {
+	inst := newTestInstance(t, "stats")
+
+	before := EngineStats()
+	ri, _ := registerInstance(inst, "res://flow:stats", DuplicateIDIgnore)
+	defer unregisterInstance(ri)
+	countInstance(FlowStatusFailed)
+
+	stats := EngineStats()
+	assert.Equal(t, before.ActiveInstances+1, stats.ActiveInstances)
+	assert.Equal(t, 1, stats.ActiveByFlow["res://flow:stats"])
+	assert.Equal(t, before.Totals[FlowStatusFailed]+1, stats.Totals[FlowStatusFailed])
+}
+
+func TestCancelInstanceContext(t *testing.T) {
+	ri := &runningInstance{}
+	ctx, cancel := context.WithCancel(context.Background())
+	ri.setCancelCtx(cancel)
+
+	ri.cancel()
+	assert.True(t, ri.isCancelled())
+	assert.NotNil(t, ctx.Err())
+}
+
+func TestPauseAfterCancel(t *testing.T) {
+	for _, signal := range []func(ri *runningInstance){(*runningInstance).cancel, (*runningInstance).requestCheckpoint} {
+		ri := &runningInstance{}
+		signal(ri)
+
+		done := make(chan struct{})
+		go func() {
+			ri.pause()
+			close(done)
+		}()
+
+		select {
+		case <-done:
+		case <-time.After(time.Second):
+			t.Fatal("instance paused after it was signalled")
+		}
+		assert.False(t, ri.paused)
+	}
+}
+
+func TestInstanceTaskStates(t *testing.T) {
+	inst := newTestInstance(t, "states",
+		&definition.TaskRep{ID: "a", ActivityCfgRep: &activity.Config{Ref: "selftest-ok"}},
+		&definition.TaskRep{ID: "b", ActivityCfgRep: &activity.Config{Ref: "selftest-fail"}},
+		&definition.TaskRep{ID: "c", ActivityCfgRep: &activity.Config{Ref: "selftest-ok"}})
+
+	ri, _ := registerInstance(inst, "res://flow:states", DuplicateIDIgnore)
+	defer unregisterInstance(ri)
+
+	states, err := GetInstanceTaskStates("states")
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]instance.TaskStatus{"a": instance.TaskStatusNotStarted, "b": instance.TaskStatusNotStarted, "c": instance.TaskStatusNotStarted}, states)
+
+	inst.Start(nil)
+	for i := 0; i < 10 && inst.Status() == model.FlowStatusActive; i++ {
+		inst.DoStep()
+	}
+
+	states, err = GetInstanceTaskStates("states")
+	assert.Nil(t, err)
+	assert.Equal(t, instance.TaskStatusDone, states["a"])
+	assert.Equal(t, instance.TaskStatusFailed, states["b"])
+	assert.Equal(t, instance.TaskStatusNotStarted, states["c"])
+}
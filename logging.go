@@ -0,0 +1,26 @@
+package flow
+
+import (
+	"github.com/project-flogo/core/support/log"
+	"github.com/project-flogo/flow/instance"
+)
+
+// logData logs the inputs or outputs of the instance as structured fields, sensitive fields are
+// redacted.  Nothing is done unless the instance's logger is at debug level.
+func logData(inst *instance.IndependentInstance, msg, key string, values map[string]interface{}) {
+	instLogger := inst.Logger()
+	if instLogger == nil || !instLogger.DebugEnabled() || instLogger.Structured() == nil {
+		return
+	}
+
+	instLogger.Structured().Debug(msg, log.FieldString("flowName", inst.Name()), log.FieldString("flowId", inst.LogID()), log.FieldAny(key, redact(values)))
+}
+
+// logIDOf returns the ID the instance is identified by in logs before the exec options have been
+// applied to it (see ExecOptions.LogID)
+func logIDOf(execOptions *instance.ExecOptions, instanceID string) string {
+	if execOptions != nil && execOptions.LogID != "" {
+		return execOptions.LogID
+	}
+	return instanceID
+}
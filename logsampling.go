@@ -0,0 +1,21 @@
+package flow
+
+import (
+	"sync/atomic"
+)
+
+// logSampleEvery is the interval at which the start and completion of instances are logged,
+// a value less than 2 logs every instance
+var logSampleEvery int
+
+var logSampleCount uint64
+
+// sampleInstanceLog returns true if the start and completion of the next instance should be
+// logged.  Failures are always logged, regardless of sampling.
+func sampleInstanceLog() bool {
+	if logSampleEvery < 2 {
+		return true
+	}
+	n := atomic.AddUint64(&logSampleCount, 1)
+	return (n-1)%uint64(logSampleEvery) == 0
+}
@@ -0,0 +1,24 @@
+package flow
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSampleInstanceLog(t *testing.T) {
+	defer func() {
+		logSampleEvery = 0
+		logSampleCount = 0
+	}()
+
+	assert.True(t, sampleInstanceLog())
+
+	logSampleEvery = 3
+	logSampleCount = 0
+	var sampled []bool
+	for i := 0; i < 6; i++ {
+		sampled = append(sampled, sampleInstanceLog())
+	}
+	assert.Equal(t, []bool{true, false, false, true, false, false}, sampled)
+}
@@ -0,0 +1,67 @@
+package flow
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/project-flogo/flow/instance"
+	flowsupport "github.com/project-flogo/flow/support"
+)
+
+var (
+	trackInstanceMemory bool
+	maxInstanceMemory   int
+)
+
+// attrFootprint returns the approximate memory footprint of the instance's attributes, along with
+// the footprint of each attribute
+func attrFootprint(inst *instance.IndependentInstance) (total int, sizes map[string]int) {
+	attrs := inst.Attributes()
+	sizes = make(map[string]int, len(attrs))
+	for name, value := range attrs {
+		size := len(name) + flowsupport.EstimateSize(value)
+		sizes[name] = size
+		total += size
+	}
+	return total, sizes
+}
+
+// checkInstanceMemory records the memory footprint of the instance, failing it if it exceeds the
+// configured maximum
+func checkInstanceMemory(ri *runningInstance) bool {
+	total, sizes := attrFootprint(ri.inst)
+	ri.setMemory(total)
+
+	if maxInstanceMemory <= 0 || total <= maxInstanceMemory {
+		return true
+	}
+
+	ri.inst.Fail(fmt.Errorf("flow instance [%s] exceeded its memory limit of %d bytes with approximately %d bytes, largest attributes: %s",
+		ri.inst.ID(), maxInstanceMemory, total, strings.Join(largestAttrs(sizes, 3), ", ")))
+	return false
+}
+
+// largestAttrs describes the n largest attributes
+func largestAttrs(sizes map[string]int, n int) []string {
+	names := make([]string, 0, len(sizes))
+	for name := range sizes {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if sizes[names[i]] == sizes[names[j]] {
+			return names[i] < names[j]
+		}
+		return sizes[names[i]] > sizes[names[j]]
+	})
+
+	if len(names) > n {
+		names = names[:n]
+	}
+
+	largest := make([]string, len(names))
+	for i, name := range names {
+		largest[i] = fmt.Sprintf("%s (%d bytes)", name, sizes[name])
+	}
+	return largest
+}
@@ -0,0 +1,14 @@
+package flow
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLargestAttrs(t *testing.T) {
+	sizes := map[string]int{"small": 10, "large": 1000, "medium": 100, "tiny": 1}
+
+	assert.Equal(t, []string{"large (1000 bytes)", "medium (100 bytes)"}, largestAttrs(sizes, 2))
+	assert.Len(t, largestAttrs(sizes, 10), 4)
+}
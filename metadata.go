@@ -2,4 +2,38 @@ package flow
 
 type Settings struct {
 	FlowURI string `md:"flowURI,required"`
+	// InputMappings maps flow inputs to fields of the trigger payload (or to expressions)
+	InputMappings map[string]string `md:"inputMappings"`
+	// OutputMapper reshapes the flow's return data, the mappings are resolved against the return data
+	OutputMapper map[string]interface{} `md:"outputMapper"`
+	// StateRecordingMode overrides the engine's state recording mode for the flow, changes are only
+	// recorded with each step when the engine's mode also records steps
+	StateRecordingMode string `md:"stateRecordingMode"`
+	// RateLimit is the maximum number of starts of the flow per second, with bursts of up to
+	// RateBurst starts.  A start waits up to RateLimitWait for the limit to allow it, after which
+	// it is rejected with a RateLimitError.
+	RateLimit     float64 `md:"rateLimit"`
+	RateBurst     int     `md:"rateBurst"`
+	RateLimitWait string  `md:"rateLimitWait"`
+	// Cacheable indicates that the flow is deterministic, so its results are cached for CacheTTL and
+	// returned in place of running the flow with equivalent inputs (see ResultCache)
+	Cacheable bool   `md:"cacheable"`
+	CacheTTL  string `md:"cacheTTL"`
+	// InstanceIDFromInput names the input from which the instance ID is derived, starts with the
+	// same value for the input get the same ID and are subject to the duplicate instance id policy
+	InstanceIDFromInput string `md:"instanceIdFromInput"`
+	// ValidateOutputs fails instances whose return data doesn't provide the outputs declared by
+	// the flow, or provides them with values that can't be coerced to the declared type
+	ValidateOutputs bool `md:"validateOutputs"`
+	// DefaultTimeout is the maximum duration of the flow's instances, it overrides the engine's
+	// defaultFlowTimeout and is overridden by the ExecOptions.MaxDuration of an invocation
+	DefaultTimeout string `md:"defaultTimeout"`
+	// AllowEmptyInputs starts the flow with the defaults of its declared inputs when it is invoked
+	// without inputs (ex. by a trigger receiving an empty body), otherwise such starts are rejected
+	// if the flow declares inputs without a default value
+	AllowEmptyInputs bool `md:"allowEmptyInputs"`
+	// MaxStepCount is the maximum number of steps of the flow's instances, after which they are
+	// stopped as a runaway loop.  By default it is derived from the number of tasks and links of
+	// the flow.
+	MaxStepCount int `md:"maxStepCount"`
 }
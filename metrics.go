@@ -0,0 +1,32 @@
+package flow
+
+import (
+	"github.com/project-flogo/flow/instance"
+	"github.com/project-flogo/flow/model"
+	"github.com/project-flogo/flow/support/metrics"
+)
+
+// recordInstanceMetrics records the metrics of a finished instance, labelled with the
+// flow name and the instance's labels
+func recordInstanceMetrics(inst *instance.IndependentInstance) {
+	if !metrics.Enabled() {
+		return
+	}
+
+	labels := make(map[string]string, len(inst.Labels())+1)
+	for name, value := range inst.Labels() {
+		labels[name] = value
+	}
+	labels["flow"] = inst.Name()
+
+	switch inst.Status() {
+	case model.FlowStatusCompleted:
+		metrics.Count(metrics.InstanceCompleted, 1, labels)
+	case model.FlowStatusFailed:
+		metrics.Count(metrics.InstanceFailed, 1, labels)
+	default:
+		return
+	}
+
+	metrics.Timing(metrics.InstanceDuration, inst.ExecutionTime(), labels)
+}
@@ -0,0 +1,45 @@
+package flow
+
+import (
+	"sync"
+
+	"github.com/project-flogo/flow/instance"
+)
+
+// StepFunc executes the next step of an instance, returning true if the instance has more work.
+// A non-nil error fails the instance.
+type StepFunc func(inst *instance.IndependentInstance) (hasWork bool, err error)
+
+// StepMiddleware wraps the execution of each step of an instance, ex. for logging, metrics or
+// authorization.  A middleware can short-circuit the step by not calling next.
+type StepMiddleware func(next StepFunc) StepFunc
+
+var (
+	middlewareMu    sync.RWMutex // protects the step middlewares
+	stepMiddlewares []StepMiddleware
+)
+
+// Use adds middlewares to the chain applied around each step.  Middlewares are applied in the
+// order they were added, the first middleware added is the outermost.
+func Use(middlewares ...StepMiddleware) {
+	middlewareMu.Lock()
+	stepMiddlewares = append(stepMiddlewares, middlewares...)
+	middlewareMu.Unlock()
+}
+
+// doStep is the StepFunc at the end of the chain
+func doStep(inst *instance.IndependentInstance) (bool, error) {
+	return inst.DoStep(), nil
+}
+
+// stepChain builds the StepFunc that applies the middlewares around each step
+func stepChain() StepFunc {
+	middlewareMu.RLock()
+	defer middlewareMu.RUnlock()
+
+	step := StepFunc(doStep)
+	for i := len(stepMiddlewares) - 1; i >= 0; i-- {
+		step = stepMiddlewares[i](step)
+	}
+	return step
+}
@@ -0,0 +1,29 @@
+package flow
+
+import (
+	"testing"
+
+	"github.com/project-flogo/flow/instance"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStepChain(t *testing.T) {
+	defer func() { stepMiddlewares = nil }()
+
+	var calls []string
+	middleware := func(name string) StepMiddleware {
+		return func(next StepFunc) StepFunc {
+			return func(inst *instance.IndependentInstance) (bool, error) {
+				calls = append(calls, name)
+				return false, nil
+			}
+		}
+	}
+	Use(middleware("first"), middleware("second"))
+
+	hasWork, err := stepChain()(nil)
+	assert.Nil(t, err)
+	assert.False(t, hasWork)
+	// the first middleware short-circuits the rest of the chain
+	assert.Equal(t, []string{"first"}, calls)
+}
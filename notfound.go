@@ -0,0 +1,37 @@
+package flow
+
+import (
+	"github.com/project-flogo/core/action"
+)
+
+// FlowNotFoundHandler is called when the URI of a flow to start can't be resolved, it either
+// returns the results to deliver in place of the flow's (ex. a default response) or an error
+type FlowNotFoundHandler func(flowURI string) (map[string]interface{}, error)
+
+var flowNotFoundHandler FlowNotFoundHandler
+
+// SetFlowNotFoundHandler sets the FlowNotFoundHandler, nil restores the default behavior of
+// returning the error encountered resolving the flow
+func SetFlowNotFoundHandler(handler FlowNotFoundHandler) {
+	flowNotFoundHandler = handler
+}
+
+// flowNotFound handles a flow that couldn't be resolved, returning the error for Run to return
+func flowNotFound(flowURI string, cause error, handler action.ResultHandler) error {
+	notFoundHandler := flowNotFoundHandler
+	if notFoundHandler == nil {
+		return cause
+	}
+
+	results, err := notFoundHandler(flowURI)
+	if err != nil {
+		return err
+	}
+
+	go func() {
+		defer handler.Done()
+		handler.HandleResult(results, nil)
+	}()
+
+	return nil
+}
@@ -0,0 +1,39 @@
+package flow
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testResultHandler struct {
+	results map[string]interface{}
+	err     error
+	done    chan struct{}
+}
+
+func (h *testResultHandler) HandleResult(results map[string]interface{}, err error) {
+	h.results = results
+	h.err = err
+}
+
+func (h *testResultHandler) Done() {
+	close(h.done)
+}
+
+func TestFlowNotFound(t *testing.T) {
+	defer SetFlowNotFoundHandler(nil)
+
+	cause := errors.New("flow not found")
+	assert.Equal(t, cause, flowNotFound("res://flow:unknown", cause, nil))
+
+	SetFlowNotFoundHandler(func(flowURI string) (map[string]interface{}, error) {
+		return map[string]interface{}{"code": 404}, nil
+	})
+
+	handler := &testResultHandler{done: make(chan struct{})}
+	assert.Nil(t, flowNotFound("res://flow:unknown", cause, handler))
+	<-handler.done
+	assert.Equal(t, 404, handler.results["code"])
+}
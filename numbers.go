@@ -0,0 +1,85 @@
+package flow
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+)
+
+// NumberMode determines how numbers in flow inputs and outputs are represented.  JSON decoding
+// produces float64 numbers, which can't represent integers beyond 2^53 exactly.
+type NumberMode string
+
+const (
+	// NumberModeFloat64 leaves numbers unchanged, this is the default
+	NumberModeFloat64 NumberMode = "float64"
+	// NumberModeInt64 converts whole numbers to int64 and json.Number values to int64 (or float64
+	// if they aren't whole numbers).  Activities that expect float64 values must coerce them.
+	NumberModeInt64 NumberMode = "int64"
+	// NumberModeJSONNumber converts numbers to json.Number, which preserves the exact value of
+	// numbers decoded using json.Decoder.UseNumber and serializes them as is.  Other numbers are
+	// converted from their float64 value, so this only helps callers that decode with UseNumber.  Activities must
+	// coerce json.Number values, as they're strings.
+	NumberModeJSONNumber NumberMode = "number"
+)
+
+var numberMode = NumberModeFloat64
+
+// ToNumberMode converts a setting value to a NumberMode
+func ToNumberMode(mode string) (NumberMode, error) {
+	switch NumberMode(mode) {
+	case "", NumberModeFloat64:
+		return NumberModeFloat64, nil
+	case NumberModeInt64, NumberModeJSONNumber:
+		return NumberMode(mode), nil
+	default:
+		return NumberModeFloat64, fmt.Errorf("unsupported number mode [%s]", mode)
+	}
+}
+
+// normalizeNumbers returns a copy of the values, including their nested objects and arrays, with
+// the numbers converted according to the mode.  The values aren't modified, as they may be shared
+// with the caller.  Numbers beyond 2^53 are only exact if the caller decoded them as json.Number
+// (see json.Decoder.UseNumber), float64 numbers have already lost their precision.
+func normalizeNumbers(values map[string]interface{}, mode NumberMode) map[string]interface{} {
+	if mode == NumberModeFloat64 || values == nil {
+		return values
+	}
+
+	normalized := make(map[string]interface{}, len(values))
+	for name, value := range values {
+		normalized[name] = normalizeNumber(value, mode)
+	}
+	return normalized
+}
+
+func normalizeNumber(val interface{}, mode NumberMode) interface{} {
+	switch t := val.(type) {
+	case map[string]interface{}:
+		return normalizeNumbers(t, mode)
+	case []interface{}:
+		normalized := make([]interface{}, len(t))
+		for i, v := range t {
+			normalized[i] = normalizeNumber(v, mode)
+		}
+		return normalized
+	case float64:
+		if mode == NumberModeJSONNumber {
+			return json.Number(strconv.FormatFloat(t, 'f', -1, 64))
+		}
+		if t == math.Trunc(t) && math.Abs(t) <= 1<<53 {
+			return int64(t)
+		}
+	case json.Number:
+		if mode == NumberModeInt64 {
+			if i, err := t.Int64(); err == nil {
+				return i
+			}
+			if f, err := t.Float64(); err == nil {
+				return f
+			}
+		}
+	}
+	return val
+}
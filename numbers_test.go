@@ -0,0 +1,33 @@
+package flow
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizeNumbers(t *testing.T) {
+	values := map[string]interface{}{
+		"id":     json.Number("9007199254740993"),
+		"amount": 10.5,
+		"count":  float64(3),
+		"items":  []interface{}{float64(1), "a"},
+	}
+
+	normalized := normalizeNumbers(values, NumberModeInt64)
+	assert.Equal(t, int64(9007199254740993), normalized["id"])
+	assert.Equal(t, 10.5, normalized["amount"])
+	assert.Equal(t, int64(3), normalized["count"])
+	assert.Equal(t, int64(1), normalized["items"].([]interface{})[0])
+
+	// the caller's values aren't modified
+	assert.Equal(t, float64(3), values["count"])
+	assert.Equal(t, float64(1), values["items"].([]interface{})[0])
+
+	normalized = normalizeNumbers(map[string]interface{}{"amount": 10.5}, NumberModeJSONNumber)
+	assert.Equal(t, json.Number("10.5"), normalized["amount"])
+
+	_, err := ToNumberMode("decimal")
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,90 @@
+package flow
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/project-flogo/core/data"
+	"github.com/project-flogo/core/data/metadata"
+	"github.com/project-flogo/core/data/schema"
+)
+
+// OpenAPISchema returns the flow's inputs and outputs as OpenAPI schema objects, keyed by "input"
+// and "output".  The schemas are plain JSON values so they can be assembled into a spec by any
+// OpenAPI library.
+func (fa *FlowAction) OpenAPISchema() (map[string]interface{}, error) {
+	return toOpenAPI(fa.ioMetadata)
+}
+
+func toOpenAPI(md *metadata.IOMetadata) (map[string]interface{}, error) {
+	if md == nil {
+		md = &metadata.IOMetadata{}
+	}
+
+	input, err := toOpenAPIObject(md.Input)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create schema for flow inputs: %s", err.Error())
+	}
+	output, err := toOpenAPIObject(md.Output)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create schema for flow outputs: %s", err.Error())
+	}
+
+	return map[string]interface{}{"input": input, "output": output}, nil
+}
+
+func toOpenAPIObject(attrs map[string]data.TypedValue) (map[string]interface{}, error) {
+	properties := make(map[string]interface{}, len(attrs))
+	for name, tv := range attrs {
+		property, err := toOpenAPIProperty(tv)
+		if err != nil {
+			return nil, fmt.Errorf("attribute '%s': %s", name, err.Error())
+		}
+		properties[name] = property
+	}
+
+	return map[string]interface{}{"type": "object", "properties": properties}, nil
+}
+
+func toOpenAPIProperty(tv data.TypedValue) (map[string]interface{}, error) {
+	if tv == nil {
+		return map[string]interface{}{}, nil
+	}
+
+	// an attribute's JSON schema is already a valid OpenAPI schema
+	if hs, ok := tv.(schema.HasSchema); ok && hs.Schema() != nil && hs.Schema().Type() == "json" {
+		var property map[string]interface{}
+		if err := json.Unmarshal([]byte(hs.Schema().Value()), &property); err != nil {
+			return nil, fmt.Errorf("invalid json schema: %s", err.Error())
+		}
+		return property, nil
+	}
+
+	switch tv.Type() {
+	case data.TypeString:
+		return map[string]interface{}{"type": "string"}, nil
+	case data.TypeInt, data.TypeInt32:
+		return map[string]interface{}{"type": "integer", "format": "int32"}, nil
+	case data.TypeInt64:
+		return map[string]interface{}{"type": "integer", "format": "int64"}, nil
+	case data.TypeFloat32:
+		return map[string]interface{}{"type": "number", "format": "float"}, nil
+	case data.TypeFloat64:
+		return map[string]interface{}{"type": "number", "format": "double"}, nil
+	case data.TypeBool:
+		return map[string]interface{}{"type": "boolean"}, nil
+	case data.TypeBytes:
+		return map[string]interface{}{"type": "string", "format": "byte"}, nil
+	case data.TypeDateTime:
+		return map[string]interface{}{"type": "string", "format": "date-time"}, nil
+	case data.TypeObject, data.TypeMap:
+		return map[string]interface{}{"type": "object"}, nil
+	case data.TypeParams:
+		return map[string]interface{}{"type": "object", "additionalProperties": map[string]interface{}{"type": "string"}}, nil
+	case data.TypeArray:
+		return map[string]interface{}{"type": "array", "items": map[string]interface{}{}}, nil
+	default:
+		// any value is allowed
+		return map[string]interface{}{}, nil
+	}
+}
@@ -0,0 +1,28 @@
+package flow
+
+import (
+	"testing"
+
+	"github.com/project-flogo/core/data"
+	"github.com/project-flogo/core/data/metadata"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestToOpenAPI(t *testing.T) {
+	md := &metadata.IOMetadata{
+		Input:  map[string]data.TypedValue{"name": data.NewTypedValue(data.TypeString, ""), "count": data.NewTypedValue(data.TypeInt64, 0)},
+		Output: map[string]data.TypedValue{"tags": data.NewTypedValue(data.TypeArray, nil)},
+	}
+
+	s, err := toOpenAPI(md)
+	assert.Nil(t, err)
+
+	input := s["input"].(map[string]interface{})
+	assert.Equal(t, "object", input["type"])
+	properties := input["properties"].(map[string]interface{})
+	assert.Equal(t, map[string]interface{}{"type": "string"}, properties["name"])
+	assert.Equal(t, map[string]interface{}{"type": "integer", "format": "int64"}, properties["count"])
+
+	output := s["output"].(map[string]interface{})
+	assert.Equal(t, "array", output["properties"].(map[string]interface{})["tags"].(map[string]interface{})["type"])
+}
@@ -0,0 +1,81 @@
+package flow
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/project-flogo/core/data"
+	"github.com/project-flogo/core/data/coerce"
+	"github.com/project-flogo/core/data/mapper"
+	"github.com/project-flogo/core/data/metadata"
+	"github.com/project-flogo/flow/definition"
+	flowsupport "github.com/project-flogo/flow/support"
+)
+
+// newOutputMapper creates a mapper for the configured output mapper, the mappings are resolved
+// against the flow's return data
+func newOutputMapper(outputMappings map[string]interface{}) (mapper.Mapper, error) {
+	if len(outputMappings) == 0 {
+		return nil, nil
+	}
+
+	return definition.GetMapperFactory().NewMapper(outputMappings)
+}
+
+// applyOutputMapper applies the output mapper to the flow's return data, the mapped values
+// replace the return data
+func applyOutputMapper(outputMapper mapper.Mapper, flowURI string, returnData map[string]interface{}) (map[string]interface{}, error) {
+	mapped, err := outputMapper.Apply(data.NewSimpleScope(returnData, nil))
+	if err != nil {
+		return nil, fmt.Errorf("unable to apply output mapper for flow [%s]: %s", flowURI, err.Error())
+	}
+
+	return mapped, nil
+}
+
+// validateOutputs checks that the return data of a flow provides each of the outputs declared by
+// its metadata, with a value that can be coerced to the declared type
+func validateOutputs(flowURI string, md *metadata.IOMetadata, returnData map[string]interface{}) error {
+	if md == nil || len(md.Output) == 0 {
+		return nil
+	}
+
+	var problems []string
+	for name, tv := range md.Output {
+		val, exists := returnData[name]
+		if !exists {
+			problems = append(problems, fmt.Sprintf("output '%s' is missing", name))
+			continue
+		}
+		if !coercible(val, tv) {
+			problems = append(problems, fmt.Sprintf("output '%s' is not of type %s", name, tv.Type()))
+		}
+	}
+
+	if len(problems) == 0 {
+		return nil
+	}
+
+	sort.Strings(problems)
+	return fmt.Errorf("flow [%s] returned invalid outputs: %s", flowURI, strings.Join(problems, ", "))
+}
+
+// coercible indicates whether the value can be coerced to the declared type, any value conforms
+// to an undeclared or untyped value and nil conforms to any type
+func coercible(val interface{}, tv data.TypedValue) bool {
+	if tv == nil || val == nil || tv.Type() == data.TypeAny || tv.Type() == data.TypeUnknown {
+		return true
+	}
+	_, err := coerce.ToType(val, tv.Type())
+	return err == nil
+}
+
+// checkOutputSize fails the outputs of the instance if their estimated serialized size exceeds
+// the maximum, rather than delivering a payload the trigger may not be able to handle
+func checkOutputSize(instanceID string, returnData map[string]interface{}, max int) error {
+	if size := flowsupport.EstimateSize(returnData); size > max {
+		return fmt.Errorf("outputs of flow instance [%s] of approximately %d bytes exceed the maximum of %d bytes", instanceID, size, max)
+	}
+	return nil
+}
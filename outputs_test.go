@@ -0,0 +1,52 @@
+package flow
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/project-flogo/core/data"
+	"github.com/project-flogo/core/data/metadata"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestApplyOutputMapper(t *testing.T) {
+	m, err := newOutputMapper(nil)
+	assert.Nil(t, err)
+	assert.Nil(t, m)
+
+	m, err = newOutputMapper(map[string]interface{}{"orderId": "=$.order.id", "status": "=$.status"})
+	assert.Nil(t, err)
+	assert.NotNil(t, m)
+
+	returnData := map[string]interface{}{"order": map[string]interface{}{"id": "1234"}, "status": "ok"}
+	outputs, err := applyOutputMapper(m, "test", returnData)
+	assert.Nil(t, err)
+	assert.Equal(t, "1234", outputs["orderId"])
+	assert.Equal(t, "ok", outputs["status"])
+	assert.NotContains(t, outputs, "order")
+}
+
+func TestValidateOutputs(t *testing.T) {
+	md := &metadata.IOMetadata{Output: map[string]data.TypedValue{
+		"count": data.NewTypedValue(data.TypeInt, nil),
+		"name":  data.NewTypedValue(data.TypeString, nil),
+	}}
+
+	assert.Nil(t, validateOutputs("res://flow:a", nil, nil))
+	assert.Nil(t, validateOutputs("res://flow:a", md, map[string]interface{}{"count": "3", "name": "a"}))
+
+	err := validateOutputs("res://flow:a", md, map[string]interface{}{"count": "three"})
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "output 'count' is not of type int")
+	assert.Contains(t, err.Error(), "output 'name' is missing")
+}
+
+func TestCheckOutputSize(t *testing.T) {
+	returnData := map[string]interface{}{"payload": strings.Repeat("x", 100)}
+
+	assert.Nil(t, checkOutputSize("inst-1", returnData, 1000))
+
+	err := checkOutputSize("inst-1", returnData, 10)
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "exceed the maximum of 10 bytes")
+}
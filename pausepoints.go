@@ -0,0 +1,45 @@
+package flow
+
+import (
+	"sync"
+)
+
+var (
+	pausePointsMu sync.RWMutex // protects the pause points
+	pausePoints   map[string]map[string]struct{}
+)
+
+// PauseAt registers a pause point, instances of the flow are suspended when the task is about to
+// be executed.  The instance's state is recorded and it can be resumed with OpResume using its ID
+// as the RunOptions.ResumeToken, it then continues with the task.
+func PauseAt(flowURI, taskID string) {
+	pausePointsMu.Lock()
+	defer pausePointsMu.Unlock()
+
+	if pausePoints == nil {
+		pausePoints = make(map[string]map[string]struct{})
+	}
+	if pausePoints[flowURI] == nil {
+		pausePoints[flowURI] = make(map[string]struct{})
+	}
+	pausePoints[flowURI][taskID] = struct{}{}
+}
+
+// RemovePauseAt removes a pause point registered with PauseAt
+func RemovePauseAt(flowURI, taskID string) {
+	pausePointsMu.Lock()
+	defer pausePointsMu.Unlock()
+
+	delete(pausePoints[flowURI], taskID)
+	if len(pausePoints[flowURI]) == 0 {
+		delete(pausePoints, flowURI)
+	}
+}
+
+func isPausePoint(flowURI, taskID string) bool {
+	pausePointsMu.RLock()
+	defer pausePointsMu.RUnlock()
+
+	_, exists := pausePoints[flowURI][taskID]
+	return exists
+}
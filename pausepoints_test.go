@@ -0,0 +1,19 @@
+package flow
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPauseAt(t *testing.T) {
+	assert.False(t, isPausePoint("res://flow:approval", "approve"))
+
+	PauseAt("res://flow:approval", "approve")
+	assert.True(t, isPausePoint("res://flow:approval", "approve"))
+	assert.False(t, isPausePoint("res://flow:approval", "notify"))
+	assert.False(t, isPausePoint("res://flow:other", "approve"))
+
+	RemovePauseAt("res://flow:approval", "approve")
+	assert.False(t, isPausePoint("res://flow:approval", "approve"))
+}
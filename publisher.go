@@ -0,0 +1,28 @@
+package flow
+
+// CompletionPublisher publishes the results of completed flow instances, ex. to a message bus
+// such as Kafka or NATS, independently of the trigger that started the flow
+type CompletionPublisher interface {
+	// Publish is called with the return data of each flow instance that completes successfully
+	Publish(instanceID, flowName string, returnData map[string]interface{}) error
+}
+
+// noopPublisher is the default CompletionPublisher, it discards the results
+type noopPublisher struct {
+}
+
+func (noopPublisher) Publish(instanceID, flowName string, returnData map[string]interface{}) error {
+	return nil
+}
+
+var completionPublisher CompletionPublisher = noopPublisher{}
+
+// SetCompletionPublisher sets the CompletionPublisher invoked when an instance completes, nil
+// restores the default, which discards the results.  Publish is called on the instance's step
+// goroutine before the result is delivered to the handler, so it should not block for long.
+func SetCompletionPublisher(p CompletionPublisher) {
+	if p == nil {
+		p = noopPublisher{}
+	}
+	completionPublisher = p
+}
@@ -0,0 +1,96 @@
+package flow
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// RateLimitError is returned when a flow can't be started because its rate limit was exceeded
+type RateLimitError struct {
+	FlowURI string
+}
+
+func (e *RateLimitError) Error() string {
+	return fmt.Sprintf("rate limit exceeded for flow [%s]", e.FlowURI)
+}
+
+// tokenBucket allows rate starts per second with bursts of up to burst starts
+type tokenBucket struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	wait   time.Duration
+	tokens float64
+	last   time.Time
+}
+
+func newTokenBucket(rate float64, burst int, wait time.Duration) *tokenBucket {
+	if burst < 1 {
+		burst = 1
+	}
+	return &tokenBucket{rate: rate, burst: float64(burst), wait: wait, tokens: float64(burst), last: time.Now()}
+}
+
+// reserve takes a token, returning how long the caller must wait for it to be available, or
+// false if that's longer than the allowed wait
+func (b *tokenBucket) reserve(now time.Time) (time.Duration, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.tokens += now.Sub(b.last).Seconds() * b.rate
+	if b.tokens > b.burst {
+		b.tokens = b.burst
+	}
+	b.last = now
+
+	if b.tokens >= 1 {
+		b.tokens--
+		return 0, true
+	}
+
+	delay := time.Duration((1 - b.tokens) / b.rate * float64(time.Second))
+	if delay > b.wait {
+		return 0, false
+	}
+	b.tokens--
+	return delay, true
+}
+
+var (
+	rateLimitersMu sync.RWMutex // protects the rate limiters
+	rateLimiters   = make(map[string]*tokenBucket)
+)
+
+// setRateLimit limits the starts of the flow to rate per second, with bursts of up to burst
+// starts.  A start waits up to wait for the limit to allow it, after which it is rejected.
+func setRateLimit(flowURI string, rate float64, burst int, wait time.Duration) {
+	rateLimitersMu.Lock()
+	defer rateLimitersMu.Unlock()
+
+	if rate <= 0 {
+		delete(rateLimiters, flowURI)
+		return
+	}
+	rateLimiters[flowURI] = newTokenBucket(rate, burst, wait)
+}
+
+// acquireRateLimit waits for the rate limit of the flow, if any, to allow a start
+func acquireRateLimit(flowURI string) error {
+	rateLimitersMu.RLock()
+	limiter := rateLimiters[flowURI]
+	rateLimitersMu.RUnlock()
+
+	if limiter == nil {
+		return nil
+	}
+
+	delay, ok := limiter.reserve(time.Now())
+	if !ok {
+		return &RateLimitError{FlowURI: flowURI}
+	}
+	if delay > 0 {
+		time.Sleep(delay)
+	}
+	return nil
+}
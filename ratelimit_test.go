@@ -0,0 +1,39 @@
+package flow
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTokenBucket(t *testing.T) {
+	now := time.Now()
+	bucket := newTokenBucket(10, 2, 0)
+	bucket.last = now
+
+	_, ok := bucket.reserve(now)
+	assert.True(t, ok)
+	_, ok = bucket.reserve(now)
+	assert.True(t, ok)
+	_, ok = bucket.reserve(now)
+	assert.False(t, ok)
+
+	// a token is added every 100ms
+	_, ok = bucket.reserve(now.Add(100 * time.Millisecond))
+	assert.True(t, ok)
+
+	bucket.wait = time.Second
+	delay, ok := bucket.reserve(now.Add(100 * time.Millisecond))
+	assert.True(t, ok)
+	assert.Equal(t, 100*time.Millisecond, delay)
+}
+
+func TestAcquireRateLimit(t *testing.T) {
+	setRateLimit("res://flow:limited", 1, 1, 0)
+	defer setRateLimit("res://flow:limited", 0, 0, 0)
+
+	assert.Nil(t, acquireRateLimit("res://flow:limited"))
+	assert.IsType(t, &RateLimitError{}, acquireRateLimit("res://flow:limited"))
+	assert.Nil(t, acquireRateLimit("res://flow:unlimited"))
+}
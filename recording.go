@@ -0,0 +1,13 @@
+package flow
+
+// RecordingPredicate decides whether the state of an instance of the flow, started with the
+// specified inputs, is recorded.  Ex. a sampled percentage or inputs with a debug flag.
+type RecordingPredicate func(flowURI string, inputs map[string]interface{}) bool
+
+var recordingPredicate RecordingPredicate
+
+// SetRecordingPredicate sets the RecordingPredicate consulted when an instance is started or
+// resumed, nil records the state of all instances
+func SetRecordingPredicate(predicate RecordingPredicate) {
+	recordingPredicate = predicate
+}
@@ -0,0 +1,68 @@
+package flow
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/project-flogo/core/app/resource"
+	flowsupport "github.com/project-flogo/flow/support"
+)
+
+// drainPollInterval is how often ReloadFlowDrain checks for running instances of the flow
+var drainPollInterval = 10 * time.Millisecond
+
+// ReloadFlow discards the loaded definition of the flow and its cached results, the definition
+// is loaded again when the flow is next started.  Running instances keep the definition they were
+// started with.  Flows defined as resources of the app (res://) are reloaded with the app.
+func ReloadFlow(flowURI string) error {
+	if flowManager == nil {
+		return fmt.Errorf("cannot reload flow, flow action not initialized")
+	}
+
+	flowURI = flowsupport.ResolveFlowURI(flowURI)
+	if strings.HasPrefix(flowURI, resource.UriScheme) {
+		return fmt.Errorf("cannot reload flow [%s], resource flows are reloaded with the app", flowURI)
+	}
+
+	flowManager.Evict(flowURI)
+	resultCache.Invalidate(flowURI)
+	return nil
+}
+
+// ReloadFlowDrain reloads the flow (see ReloadFlow) once its running instances have finished, so
+// instances of the old and new definitions don't run side by side.  If instances are still running
+// after the timeout the flow is reloaded anyway, drained indicates whether all instances finished.
+// New instances can be started while draining.
+func ReloadFlowDrain(flowURI string, timeout time.Duration) (drained bool, err error) {
+	resolved := flowsupport.ResolveFlowURI(flowURI)
+	deadline := time.Now().Add(timeout)
+
+	for {
+		drained = countRunningInstances(resolved) == 0
+		if drained || !time.Now().Before(deadline) {
+			break
+		}
+		time.Sleep(drainPollInterval)
+	}
+
+	if !drained {
+		logger.Warnf("Reloading flow [%s] with %d instances still running", resolved, countRunningInstances(resolved))
+	}
+
+	return drained, ReloadFlow(flowURI)
+}
+
+// countRunningInstances returns the number of running instances of the flow
+func countRunningInstances(flowURI string) int {
+	riMu.RLock()
+	defer riMu.RUnlock()
+
+	count := 0
+	for _, ri := range runningInstances {
+		if ri.flowURI == flowURI {
+			count++
+		}
+	}
+	return count
+}
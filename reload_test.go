@@ -0,0 +1,26 @@
+package flow
+
+import (
+	"testing"
+	"time"
+
+	flowsupport "github.com/project-flogo/flow/support"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReloadFlowDrain(t *testing.T) {
+	prev := flowManager
+	flowManager = flowsupport.NewFlowManager(nil)
+	defer func() { flowManager = prev }()
+
+	assert.NotNil(t, ReloadFlow("res://flow:drain"))
+
+	ri, _ := registerInstance(newTestInstance(t, "drain"), "file://drain.json", DuplicateIDIgnore)
+	assert.Equal(t, 1, countRunningInstances("file://drain.json"))
+	time.AfterFunc(20*time.Millisecond, func() { unregisterInstance(ri) })
+
+	drained, err := ReloadFlowDrain("file://drain.json", time.Second)
+	assert.Nil(t, err)
+	assert.True(t, drained)
+	assert.Equal(t, 0, countRunningInstances("file://drain.json"))
+}
@@ -0,0 +1,214 @@
+package flow
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/project-flogo/core/action"
+	"github.com/project-flogo/flow/instance"
+	"github.com/project-flogo/flow/model"
+	"github.com/project-flogo/flow/state"
+	"github.com/project-flogo/flow/state/change"
+)
+
+// RerunFromFailure continues the failed instance with the specified ID from the step that failed,
+// using the state recorded before that step with the overrides applied (see RunOptions.AttrOverrides).
+// The instance is run as a new instance whose ID is returned, its results are delivered to the
+// handler.  The last recorded step of the instance must be the failure of one of its tasks, failures
+// within subflows can't be rerun.
+func (fa *FlowAction) RerunFromFailure(ctx context.Context, instanceID string, overrides map[string]interface{}, handler action.ResultHandler) (string, error) {
+	ro, err := rerunOptions(instanceID, overrides)
+	if err != nil {
+		return "", err
+	}
+
+	ro.PreservedInstanceId = idGenerator.NextAsString()
+	inputs := map[string]interface{}{"_run_options": ro}
+
+	if err := fa.Run(ctx, inputs, handler); err != nil {
+		return "", err
+	}
+	return ro.PreservedInstanceId, nil
+}
+
+// rerunOptions builds the options to restart the instance from its recorded failed step
+func rerunOptions(instanceID string, overrides map[string]interface{}) (*instance.RunOptions, error) {
+	steps, err := GetInstanceHistory(instanceID)
+	if err != nil {
+		return nil, err
+	}
+
+	failedStep, failedTask, err := failurePoint(instanceID, steps)
+	if err != nil {
+		return nil, err
+	}
+
+	var before []*state.Step
+	for _, step := range steps {
+		if step.Id < failedStep {
+			before = append(before, step)
+		}
+	}
+
+	snapshot := state.StepsToSnapshot(instanceID, before)
+	addSampledOutChanges(snapshot, steps[len(steps)-1], failedTask)
+
+	initialState, err := instanceFromSnapshot(snapshot, failedTask)
+	if err != nil {
+		return nil, fmt.Errorf("unable to rerun instance [%s]: %s", instanceID, err.Error())
+	}
+
+	return &instance.RunOptions{
+		Op:            instance.OpRestart,
+		FlowURI:       initialState.FlowURI(),
+		InitStepId:    failedStep,
+		InitialState:  initialState,
+		Rerun:         true,
+		AttrOverrides: overrides,
+	}, nil
+}
+
+// failurePoint returns the ID of the step the instance failed in and the ID of the task that failed,
+// the failed step must be the last recorded step of the instance
+func failurePoint(instanceID string, steps []*state.Step) (int, string, error) {
+	if len(steps) == 0 {
+		return 0, "", fmt.Errorf("unable to rerun instance [%s], no steps recorded", instanceID)
+	}
+
+	last := steps[len(steps)-1]
+	flowChg := last.FlowChanges[0]
+	if flowChg == nil || flowChg.Status != int(model.FlowStatusFailed) {
+		return 0, "", fmt.Errorf("unable to rerun instance [%s], its last recorded step %d is not a failure", instanceID, last.Id)
+	}
+
+	for _, chg := range last.FlowChanges {
+		if chg.SubflowId > 0 {
+			return 0, "", fmt.Errorf("unable to rerun instance [%s], it failed in a subflow", instanceID)
+		}
+	}
+
+	for taskID, taskChg := range flowChg.Tasks {
+		if taskChg.ChgType != change.Delete && taskChg.Status == int(model.TaskStatusFailed) {
+			return last.Id, taskID, nil
+		}
+	}
+
+	return 0, "", fmt.Errorf("unable to rerun instance [%s], no failed task recorded in step %d", instanceID, last.Id)
+}
+
+// addSampledOutChanges adds the changes of the steps that were sampled out before the failed step
+// (see state.Sampler) to the snapshot.  Their changes are recorded with the failed step, so the
+// failed task is only queued in it.  In that case the queue changes of the failed step, with the
+// failed task queued, and the attributes it set are applied to the snapshot.
+func addSampledOutChanges(snapshot *state.Snapshot, failed *state.Step, failedTask string) {
+	for _, wi := range snapshot.WorkQueue {
+		if wi.SubflowId == 0 && wi.TaskId == failedTask {
+			return
+		}
+	}
+
+	queue := make(map[int]*state.WorkItem, len(snapshot.WorkQueue))
+	for _, wi := range snapshot.WorkQueue {
+		queue[wi.ID] = wi
+	}
+	for id, queueChg := range failed.QueueChanges {
+		if queueChg.SubflowId != 0 {
+			continue
+		}
+		if queueChg.ChgType == change.Delete && queueChg.TaskId != failedTask {
+			delete(queue, id)
+		} else {
+			queue[id] = &state.WorkItem{ID: id, TaskId: queueChg.TaskId}
+		}
+	}
+	snapshot.WorkQueue = make([]*state.WorkItem, 0, len(queue))
+	for _, wi := range queue {
+		snapshot.WorkQueue = append(snapshot.WorkQueue, wi)
+	}
+
+	if flowChg := failed.FlowChanges[0]; flowChg != nil && len(flowChg.Attrs) > 0 {
+		if snapshot.Attrs == nil {
+			snapshot.Attrs = make(map[string]interface{}, len(flowChg.Attrs))
+		}
+		for name, value := range flowChg.Attrs {
+			snapshot.Attrs[name] = value
+		}
+	}
+}
+
+// instanceFromSnapshot rebuilds the instance state from the snapshot.  If a failed task is
+// specified, its work item is moved to the head of the queue so it runs first when restarted.
+func instanceFromSnapshot(snapshot *state.Snapshot, failedTask string) (*instance.IndependentInstance, error) {
+	if snapshot.FlowURI == "" {
+		return nil, fmt.Errorf("flow of the instance not recorded")
+	}
+
+	type serWorkItem struct {
+		ID        int    `json:"id"`
+		TaskID    string `json:"taskID"`
+		SubFlowID int    `json:"subFlowId"`
+	}
+	type serLink struct {
+		LinkID int `json:"linkId"`
+		Status int `json:"status"`
+	}
+
+	var failedItem *serWorkItem
+	var queue []*serWorkItem
+	for _, wi := range snapshot.WorkQueue {
+		if wi.SubflowId > 0 {
+			return nil, fmt.Errorf("subflows are not supported")
+		}
+		item := &serWorkItem{ID: wi.ID, TaskID: wi.TaskId}
+		if failedTask != "" && wi.TaskId == failedTask && failedItem == nil {
+			failedItem = item
+			continue
+		}
+		queue = append(queue, item)
+	}
+	if failedTask != "" && failedItem == nil {
+		return nil, fmt.Errorf("task [%s] was not queued before the failed step", failedTask)
+	}
+
+	sort.SliceStable(queue, func(i, j int) bool {
+		return queue[i].ID < queue[j].ID
+	})
+	if failedItem != nil {
+		queue = append([]*serWorkItem{failedItem}, queue...)
+	}
+
+	links := make([]*serLink, 0, len(snapshot.Links))
+	for _, link := range snapshot.Links {
+		links = append(links, &serLink{LinkID: link.Id, Status: link.Status})
+	}
+
+	tasks := snapshot.Tasks
+	hasFailedTask := failedTask == ""
+	for _, task := range tasks {
+		hasFailedTask = hasFailedTask || task.Id == failedTask
+	}
+	if !hasFailedTask {
+		tasks = append(tasks, &state.Task{Id: failedTask, Status: int(model.TaskStatusReady)})
+	}
+
+	data, err := json.Marshal(map[string]interface{}{
+		"id":        snapshot.Id,
+		"status":    model.FlowStatusActive,
+		"flowUri":   snapshot.FlowURI,
+		"attrs":     snapshot.Attrs,
+		"workQueue": queue,
+		"tasks":     tasks,
+		"links":     links,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	inst := &instance.IndependentInstance{}
+	if err := json.Unmarshal(data, inst); err != nil {
+		return nil, err
+	}
+	return inst, nil
+}
@@ -0,0 +1,108 @@
+package flow
+
+import (
+	"testing"
+	"time"
+
+	"github.com/project-flogo/core/support/log"
+	"github.com/project-flogo/flow/instance"
+	"github.com/project-flogo/flow/model"
+	"github.com/project-flogo/flow/state"
+	"github.com/project-flogo/flow/state/change"
+	"github.com/stretchr/testify/assert"
+)
+
+type historyRecorder struct {
+	steps []*state.Step
+}
+
+func (r *historyRecorder) RecordStart(state *state.FlowState) error      { return nil }
+func (r *historyRecorder) RecordSnapshot(snapshot *state.Snapshot) error { return nil }
+func (r *historyRecorder) RecordDone(state *state.FlowState) error       { return nil }
+
+func (r *historyRecorder) RecordStep(step *state.Step) error {
+	r.steps = append(r.steps, step)
+	return nil
+}
+
+func (r *historyRecorder) GetSteps(flowID string) ([]*state.Step, error) {
+	if len(r.steps) == 0 {
+		return nil, state.ErrInstanceNotFound
+	}
+	return r.steps, nil
+}
+
+func TestRerunOptions(t *testing.T) {
+	recorder := &historyRecorder{steps: []*state.Step{
+		{Id: 1, FlowChanges: map[int]*change.Flow{
+			0: {NewFlow: true, FlowURI: "file://rerun.json", Status: int(model.FlowStatusActive), Attrs: map[string]interface{}{"orderId": "1"}},
+		}, QueueChanges: map[int]*change.Queue{
+			1: {ChgType: change.Add, TaskId: "charge"},
+		}},
+		{Id: 2, FlowChanges: map[int]*change.Flow{
+			0: {Status: int(model.FlowStatusFailed), Tasks: map[string]*change.Task{
+				"charge": {ChgType: change.Add, Status: int(model.TaskStatusFailed)},
+			}},
+		}, QueueChanges: map[int]*change.Queue{
+			1: {ChgType: change.Delete},
+		}},
+	}}
+
+	prev := stateRecorder
+	stateRecorder = recorder
+	defer func() { stateRecorder = prev }()
+
+	ro, err := rerunOptions("rerun-1", map[string]interface{}{"orderId": "2"})
+	assert.Nil(t, err)
+	assert.Equal(t, instance.OpRestart, ro.Op)
+	assert.Equal(t, 2, ro.InitStepId)
+	assert.Equal(t, "file://rerun.json", ro.FlowURI)
+	assert.True(t, ro.Rerun)
+	assert.Equal(t, "2", ro.AttrOverrides["orderId"])
+	assert.Equal(t, model.FlowStatusActive, ro.InitialState.Status())
+
+	attrs := ro.InitialState.Attributes()
+	assert.Equal(t, "1", attrs["orderId"])
+
+	// the last step must be the failure
+	recorder.steps = recorder.steps[:1]
+	_, err = rerunOptions("rerun-1", nil)
+	assert.NotNil(t, err)
+
+	recorder.steps = nil
+	_, err = rerunOptions("rerun-1", nil)
+	assert.Equal(t, state.ErrInstanceNotFound, err)
+}
+
+func TestRerunSampledOut(t *testing.T) {
+	// only the first step and the failure are recorded
+	defer enableSampling(state.EveryNthSampler(100))()
+
+	recorder := &historyRecorder{}
+	inst := newSampledInstance(t, "sampled-3", recorder)
+	inst.Start(nil)
+	assert.Nil(t, inst.RecordState(time.Now()))
+	for i := 0; i < 10 && inst.Status() == model.FlowStatusActive; i++ {
+		inst.DoStep()
+		assert.Nil(t, inst.RecordState(time.Now()))
+	}
+	assert.Equal(t, model.FlowStatusFailed, inst.Status())
+	assert.Len(t, recorder.steps, 2)
+
+	prev := stateRecorder
+	stateRecorder = recorder
+	defer func() { stateRecorder = prev }()
+
+	ro, err := rerunOptions("sampled-3", nil)
+	assert.Nil(t, err)
+	assert.Equal(t, inst.StepID(), ro.InitStepId)
+
+	rerun := ro.InitialState
+	assert.Nil(t, rerun.Restart(log.RootLogger(), "sampled-4", ro.InitStepId))
+	rerun.DoStep()
+
+	trace := rerun.ExecutionTrace()
+	assert.Len(t, trace, 1)
+	assert.Equal(t, "d", trace[0].TaskID)
+	assert.Equal(t, ro.InitStepId+1, trace[0].StepID)
+}
@@ -0,0 +1,48 @@
+package flow
+
+import (
+	"sync"
+)
+
+var (
+	reservedMu  sync.Mutex // protects the reserved IDs
+	reservedIDs = make(map[string]struct{})
+)
+
+// ReserveInstanceID reserves an instance ID ahead of starting a flow, ex. so that it can be
+// persisted with an external record first.  The flow is then started with the ID as its
+// RunOptions.PreservedInstanceId, which claims the reservation.  An empty string is returned
+// if the flow action hasn't been initialized.
+func ReserveInstanceID() string {
+	if idGenerator == nil {
+		return ""
+	}
+
+	reservedMu.Lock()
+	defer reservedMu.Unlock()
+
+	for {
+		id := idGenerator.NextAsString()
+		if _, reserved := reservedIDs[id]; reserved || getRunningInstance(id) != nil {
+			continue
+		}
+		reservedIDs[id] = struct{}{}
+		return id
+	}
+}
+
+// ReleaseInstanceID releases a reserved instance ID that will not be used
+func ReleaseInstanceID(id string) {
+	reservedMu.Lock()
+	delete(reservedIDs, id)
+	reservedMu.Unlock()
+}
+
+// IsInstanceIDReserved checks if the instance ID is reserved and hasn't been claimed yet
+func IsInstanceIDReserved(id string) bool {
+	reservedMu.Lock()
+	defer reservedMu.Unlock()
+
+	_, reserved := reservedIDs[id]
+	return reserved
+}
@@ -0,0 +1,24 @@
+package flow
+
+import (
+	"testing"
+
+	"github.com/project-flogo/core/support"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReserveInstanceID(t *testing.T) {
+	if idGenerator == nil {
+		idGenerator, _ = support.NewGenerator()
+	}
+
+	id1 := ReserveInstanceID()
+	id2 := ReserveInstanceID()
+	assert.NotEqual(t, "", id1)
+	assert.NotEqual(t, id1, id2)
+	assert.True(t, IsInstanceIDReserved(id1))
+
+	ReleaseInstanceID(id1)
+	assert.False(t, IsInstanceIDReserved(id1))
+	ReleaseInstanceID(id2)
+}
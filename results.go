@@ -0,0 +1,22 @@
+package flow
+
+// FlowStatusKey is the reserved result key that contains the final status of the flow
+// instance. It is always set by the engine, overriding any flow output of the same name.
+const FlowStatusKey = "_flow_status"
+
+const (
+	FlowStatusCompleted = "completed"
+	FlowStatusFailed    = "failed"
+	FlowStatusCancelled = "cancelled"
+	FlowStatusSuspended = "suspended"
+)
+
+// withFlowStatus returns the results with the flow status set, the results map is
+// created if necessary
+func withFlowStatus(results map[string]interface{}, status string) map[string]interface{} {
+	if results == nil {
+		results = make(map[string]interface{}, 1)
+	}
+	results[FlowStatusKey] = status
+	return results
+}
@@ -0,0 +1,91 @@
+package flow
+
+import (
+	"context"
+	"time"
+
+	"github.com/project-flogo/core/action"
+	"github.com/project-flogo/flow/instance"
+)
+
+// shouldRetryFlow checks if a flow that failed with the specified error should be retried
+func shouldRetryFlow(retry *instance.FlowRetry, retryCount int, err error) bool {
+	if retry == nil || err == nil || retryCount >= retry.MaxAttempts {
+		return false
+	}
+
+	if len(retry.ErrorClasses) == 0 {
+		return true
+	}
+
+	class := errorClass(err)
+	for _, c := range retry.ErrorClasses {
+		if c == class {
+			return true
+		}
+	}
+	return false
+}
+
+// retryBackoff returns the delay before the next retry of the flow
+func retryBackoff(retry *instance.FlowRetry, retryCount int) time.Duration {
+	return retry.Backoff << uint(retryCount)
+}
+
+// retryFlow schedules a new instance of the failed flow with the same inputs, the new instance
+// delivers its results to the caller's handler.  The retry keeps the values of the context, but
+// not its deadline or cancellation, as it outlives the request that started the flow.
+func (fa *FlowAction) retryFlow(ctx context.Context, failed *instance.IndependentInstance, ro *instance.RunOptions, inputs map[string]interface{}, handler action.ResultHandler) bool {
+	backoff := retryBackoff(ro.ExecOptions.FlowRetry, ro.RetryCount-1)
+	retryCtx := detachedContext{ctx}
+
+	err := scheduler.Schedule(failed.ID(), time.Now().Add(backoff), func() {
+		inputs["_run_options"] = ro
+		if err := fa.Run(retryCtx, inputs, handler); err != nil {
+			logger.Errorf("Unable to retry Flow Instance [%s]: %v", failed.ID(), err)
+			handler.HandleResult(withFlowStatus(nil, FlowStatusFailed), err)
+			handler.Done()
+		}
+	})
+	if err != nil {
+		logger.Errorf("Unable to schedule retry of Flow Instance [%s]: %v", failed.ID(), err)
+		return false
+	}
+
+	logger.Infof("Flow Instance [%s] failed, retrying flow (attempt %d of %d) in %s", failed.ID(), ro.RetryCount, ro.ExecOptions.FlowRetry.MaxAttempts, backoff)
+	return true
+}
+
+// retryResultHandler is the innermost handler of an instance that can be retried, the caller's
+// Done is left to the retry once the instance has been retried
+type retryResultHandler struct {
+	handler action.ResultHandler
+	retried bool
+}
+
+func (h *retryResultHandler) HandleResult(resultData map[string]interface{}, err error) {
+	h.handler.HandleResult(resultData, err)
+}
+
+func (h *retryResultHandler) Done() {
+	if !h.retried {
+		h.handler.Done()
+	}
+}
+
+// detachedContext keeps the values of its parent, without its deadline and cancellation
+type detachedContext struct {
+	context.Context
+}
+
+func (detachedContext) Deadline() (time.Time, bool) {
+	return time.Time{}, false
+}
+
+func (detachedContext) Done() <-chan struct{} {
+	return nil
+}
+
+func (detachedContext) Err() error {
+	return nil
+}
@@ -0,0 +1,112 @@
+package flow
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/project-flogo/core/activity"
+	"github.com/project-flogo/core/support"
+	"github.com/project-flogo/core/support/log"
+	"github.com/project-flogo/flow/definition"
+	"github.com/project-flogo/flow/instance"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShouldRetryFlow(t *testing.T) {
+	err := instance.NewErrorWithCode("503", "unavailable", nil)
+
+	assert.False(t, shouldRetryFlow(nil, 0, err))
+
+	retry := &instance.FlowRetry{MaxAttempts: 2}
+	assert.True(t, shouldRetryFlow(retry, 1, err))
+	assert.False(t, shouldRetryFlow(retry, 2, err))
+
+	retry.ErrorClasses = []string{"503"}
+	assert.True(t, shouldRetryFlow(retry, 0, err))
+	assert.False(t, shouldRetryFlow(retry, 0, errors.New("bad request")))
+}
+
+func TestRetryBackoff(t *testing.T) {
+	retry := &instance.FlowRetry{Backoff: time.Second}
+	assert.Equal(t, time.Second, retryBackoff(retry, 0))
+	assert.Equal(t, 4*time.Second, retryBackoff(retry, 2))
+}
+
+func TestRetryResultHandler(t *testing.T) {
+	caller := &testResultHandler{done: make(chan struct{})}
+
+	// the results of a retried instance are left to the retry
+	h := &retryResultHandler{handler: caller, retried: true}
+	h.Done()
+	select {
+	case <-caller.done:
+		t.Fatal("caller's handler done before the retry")
+	default:
+	}
+
+	h = &retryResultHandler{handler: caller}
+	h.HandleResult(map[string]interface{}{"a": 1}, nil)
+	h.Done()
+	<-caller.done
+	assert.Equal(t, 1, caller.results["a"])
+}
+
+// idResultHandler collects the results delivered to the caller of a fire and forget instance
+type idResultHandler struct {
+	mu      sync.Mutex
+	results []map[string]interface{}
+	done    chan struct{}
+}
+
+func (h *idResultHandler) HandleResult(results map[string]interface{}, err error) {
+	h.mu.Lock()
+	h.results = append(h.results, results)
+	h.mu.Unlock()
+}
+
+func (h *idResultHandler) Done() {
+	close(h.done)
+}
+
+func TestRetryFireAndForget(t *testing.T) {
+	logger = log.ChildLogger(log.RootLogger(), "flow")
+	if idGenerator == nil {
+		idGenerator, _ = support.NewGenerator()
+	}
+	def := newTestDefinition(t, "retried", &definition.TaskRep{ID: "a", ActivityCfgRep: &activity.Config{Ref: "selftest-fail"}})
+
+	fa := &FlowAction{flowURI: "res://flow:retried", resFlow: def, allowEmptyInputs: true}
+	handler := &idResultHandler{done: make(chan struct{})}
+	ro := &instance.RunOptions{Op: instance.OpStart, ExecOptions: &instance.ExecOptions{FireAndForget: true,
+		FlowRetry: &instance.FlowRetry{MaxAttempts: 2, Backoff: time.Millisecond}}}
+	assert.Nil(t, fa.Run(context.Background(), map[string]interface{}{"_run_options": ro}, handler))
+
+	select {
+	case <-handler.done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("retried instance not done")
+	}
+
+	// only the first attempt returns the ID, the results of the failed attempts are discarded
+	handler.mu.Lock()
+	defer handler.mu.Unlock()
+	if assert.Len(t, handler.results, 1) {
+		assert.NotEmpty(t, handler.results[0]["id"])
+	}
+}
+
+func TestDetachedContext(t *testing.T) {
+	type key struct{}
+	parent, cancel := context.WithTimeout(context.WithValue(context.Background(), key{}, "v"), time.Millisecond)
+	cancel()
+
+	ctx := detachedContext{parent}
+	assert.Nil(t, ctx.Err())
+	assert.Nil(t, ctx.Done())
+	_, hasDeadline := ctx.Deadline()
+	assert.False(t, hasDeadline)
+	assert.Equal(t, "v", ctx.Value(key{}))
+}
@@ -0,0 +1,67 @@
+//go:build go1.18
+// +build go1.18
+
+package flow
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	"github.com/project-flogo/core/data/metadata"
+)
+
+// RunInto runs the flow and decodes its outputs into a value of type T, which must be a struct (or
+// a pointer to one).  Outputs are matched to the struct's fields using their 'md' tag, or their
+// name, and coerced to the field's type.  If the flow fails its error is returned, otherwise a
+// decode error identifies the output type that couldn't be decoded.
+func RunInto[T any](ctx context.Context, fa *FlowAction, inputs map[string]interface{}) (T, error) {
+	var result T
+
+	results, err := fa.RunAsync(ctx, inputs)
+	if err != nil {
+		return result, err
+	}
+
+	var r *Result
+	select {
+	case r = <-results:
+	case <-ctx.Done():
+		return result, ctx.Err()
+	}
+
+	if r == nil {
+		return result, fmt.Errorf("flow finished without results")
+	}
+	if r.Err != nil {
+		return result, r.Err
+	}
+
+	err = decodeOutputs(r.Data, &result)
+	return result, err
+}
+
+// decodeOutputs decodes the outputs into the struct pointed to by target, or into a new struct when
+// target points to a struct pointer
+func decodeOutputs(outputs map[string]interface{}, target interface{}) (err error) {
+	v := reflect.ValueOf(target).Elem()
+	if v.Kind() == reflect.Ptr && v.Type().Elem().Kind() == reflect.Struct {
+		v.Set(reflect.New(v.Type().Elem()))
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return fmt.Errorf("unable to decode flow outputs into %s, a struct is required", v.Type())
+	}
+
+	// MapToStruct panics on fields whose type can't be set from the coerced value
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("unable to decode flow outputs into %s: %v", v.Type(), r)
+		}
+	}()
+
+	if err := metadata.MapToStruct(outputs, v.Addr().Interface(), false); err != nil {
+		return fmt.Errorf("unable to decode flow outputs into %s: %s", v.Type(), err.Error())
+	}
+	return nil
+}
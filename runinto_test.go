@@ -0,0 +1,34 @@
+//go:build go1.18
+// +build go1.18
+
+package flow
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type orderResult struct {
+	OrderID string `md:"orderId"`
+	Total   int    `md:"total"`
+}
+
+func TestDecodeOutputs(t *testing.T) {
+	outputs := map[string]interface{}{"orderId": "1234", "total": "42", FlowStatusKey: FlowStatusCompleted}
+
+	var result orderResult
+	assert.Nil(t, decodeOutputs(outputs, &result))
+	assert.Equal(t, orderResult{OrderID: "1234", Total: 42}, result)
+
+	var ptr *orderResult
+	assert.Nil(t, decodeOutputs(outputs, &ptr))
+	assert.Equal(t, 42, ptr.Total)
+
+	err := decodeOutputs(map[string]interface{}{"total": "many"}, &result)
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "unable to decode flow outputs into flow.orderResult")
+
+	var count int
+	assert.NotNil(t, decodeOutputs(outputs, &count))
+}
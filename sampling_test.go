@@ -0,0 +1,88 @@
+package flow
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/project-flogo/core/activity"
+	"github.com/project-flogo/core/support/log"
+	"github.com/project-flogo/flow/definition"
+	"github.com/project-flogo/flow/instance"
+	"github.com/project-flogo/flow/state"
+	flowsupport "github.com/project-flogo/flow/support"
+	"github.com/stretchr/testify/assert"
+)
+
+const sampledFlowURI = "local://sampled"
+
+// newSampledInstance creates an instance of a flow of four tasks whose steps are recorded by the
+// recorder, the flow can be looked up so the instance can be restarted
+func newSampledInstance(t *testing.T, id string, recorder state.Recorder) *instance.IndependentInstance {
+	def := newTestDefinition(t, "sampled",
+		&definition.TaskRep{ID: "a", ActivityCfgRep: &activity.Config{Ref: "selftest-ok"}},
+		&definition.TaskRep{ID: "b", ActivityCfgRep: &activity.Config{Ref: "selftest-ok"}},
+		&definition.TaskRep{ID: "c", ActivityCfgRep: &activity.Config{Ref: "selftest-ok"}},
+		&definition.TaskRep{ID: "d", ActivityCfgRep: &activity.Config{Ref: "selftest-fail"}})
+
+	cache := flowsupport.NewMapDefinitionCache()
+	cache.Put(sampledFlowURI, def)
+	fm := flowsupport.NewFlowManager(nil)
+	fm.SetDefinitionCache(cache)
+	flowsupport.InitDefaultDefLookup(fm, nil)
+
+	inst, err := instance.NewIndependentInstance(id, sampledFlowURI, def,
+		instance.NewStateInstanceRecorder(recorder, state.RecordingModeStep, false), log.RootLogger())
+	assert.Nil(t, err)
+	return inst
+}
+
+func enableSampling(sampler state.Sampler) func() {
+	instance.EnableChangeTracking(true, state.RecordingModeStep)
+	instance.SetRecordingSampler(sampler)
+	return func() {
+		instance.EnableChangeTracking(false, state.RecordingModeOff)
+		instance.SetRecordingSampler(nil)
+	}
+}
+
+func TestSampledStepIDs(t *testing.T) {
+	defer enableSampling(state.EveryNthSampler(2))()
+
+	recorder := &historyRecorder{}
+	inst := newSampledInstance(t, "sampled-1", recorder)
+	inst.Start(nil)
+	assert.Nil(t, inst.RecordState(time.Now()))
+
+	// run a, b and c, keeping the state of the instance after b
+	var afterB []byte
+	for inst.StepID() < 3 {
+		inst.DoStep()
+		assert.Nil(t, inst.RecordState(time.Now()))
+		if inst.StepID() == 2 {
+			var err error
+			afterB, err = json.Marshal(inst)
+			assert.Nil(t, err)
+		}
+	}
+
+	// the step of b was sampled out, the recorded steps keep the IDs of the instance's steps
+	var ids []int
+	for _, step := range recorder.steps {
+		ids = append(ids, step.Id)
+	}
+	assert.Equal(t, []int{0, 1, 3}, ids)
+
+	// restart after b, the steps are numbered from the step it was restarted at
+	restarted := &instance.IndependentInstance{}
+	assert.Nil(t, json.Unmarshal(afterB, restarted))
+	restartRecorder := &historyRecorder{}
+	restarted.SetInstanceRecorder(instance.NewStateInstanceRecorder(restartRecorder, state.RecordingModeStep, false))
+	assert.Nil(t, restarted.Restart(log.RootLogger(), "sampled-2", 2))
+	restarted.CurrentStep(true)
+
+	restarted.DoStep()
+	assert.Nil(t, restarted.RecordState(time.Now()))
+	assert.Equal(t, 3, restarted.StepID())
+	assert.Equal(t, 3, restartRecorder.steps[len(restartRecorder.steps)-1].Id)
+}
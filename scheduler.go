@@ -0,0 +1,32 @@
+package flow
+
+import "time"
+
+// Scheduler schedules the continuation of flow instances whose execution has been delayed
+// by an activity.  The step goroutine of a delayed instance is released until the scheduled
+// time, at which point the continuation must be called to resume executing the instance.
+type Scheduler interface {
+	// Schedule arranges for the continuation to be called at the specified time
+	Schedule(instanceID string, at time.Time, continuation func()) error
+}
+
+// timerScheduler is the default in-process Scheduler
+type timerScheduler struct {
+}
+
+func (timerScheduler) Schedule(instanceID string, at time.Time, continuation func()) error {
+	time.AfterFunc(time.Until(at), continuation)
+	return nil
+}
+
+var scheduler Scheduler = timerScheduler{}
+
+// SetScheduler sets the Scheduler used to continue delayed instances, nil restores the default
+// in-process timer.  The continuation runs in this process, so a durable implementation (ex.
+// one backed by a queue) must still invoke it from this process once the delay has elapsed.
+func SetScheduler(s Scheduler) {
+	if s == nil {
+		s = timerScheduler{}
+	}
+	scheduler = s
+}
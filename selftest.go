@@ -0,0 +1,45 @@
+package flow
+
+import (
+	"fmt"
+
+	"github.com/project-flogo/flow/model"
+)
+
+// SelfTest runs the flow with the sample inputs, against its live dependencies, and discards its
+// results.  It returns nil if the flow completed, otherwise the error it failed with.  Use
+// SimulateFlow to check a flow without touching real systems.
+func SelfTest(flowURI string, sampleInputs map[string]interface{}) error {
+	stepper, err := StartStepper(flowURI, sampleInputs)
+	if err != nil {
+		return err
+	}
+
+	return runSelfTest(stepper)
+}
+
+func runSelfTest(stepper *Stepper) error {
+	inst := stepper.Instance()
+
+	for stepCount := 0; !stepper.Done(); stepCount++ {
+		if stepCount >= maxStepCount {
+			return fmt.Errorf("self test of flow [%s] did not complete within %d steps", inst.FlowURI(), maxStepCount)
+		}
+		if _, err := stepper.Step(); err != nil {
+			return err
+		}
+	}
+
+	switch inst.Status() {
+	case model.FlowStatusCompleted:
+		_, err := inst.GetReturnData()
+		return err
+	case model.FlowStatusFailed:
+		if err := inst.GetError(); err != nil {
+			return err
+		}
+		return fmt.Errorf("self test of flow [%s] failed", inst.FlowURI())
+	default:
+		return fmt.Errorf("self test of flow [%s] did not complete, status %d", inst.FlowURI(), inst.Status())
+	}
+}
@@ -0,0 +1,75 @@
+package flow
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/project-flogo/core/activity"
+	"github.com/project-flogo/core/support/log"
+	"github.com/project-flogo/flow/definition"
+	"github.com/project-flogo/flow/instance"
+	"github.com/project-flogo/flow/model"
+	"github.com/project-flogo/flow/model/simple"
+	"github.com/stretchr/testify/assert"
+)
+
+type selfTestActivity struct {
+	err error
+}
+
+func (a *selfTestActivity) Metadata() *activity.Metadata {
+	return &activity.Metadata{}
+}
+
+func (a *selfTestActivity) Eval(ctx activity.Context) (bool, error) {
+	return a.err == nil, a.err
+}
+
+func init() {
+	_ = activity.LegacyRegister("selftest-ok", &selfTestActivity{})
+	_ = activity.LegacyRegister("selftest-fail", &selfTestActivity{err: errors.New("dependency unavailable")})
+}
+
+// newTestDefinition creates the definition of a flow whose tasks are linked in the order they are
+// specified
+func newTestDefinition(t *testing.T, name string, tasks ...*definition.TaskRep) *definition.Definition {
+	model.RegisterDefault(simple.New())
+	defRep := &definition.DefinitionRep{Name: name, Tasks: tasks}
+	for i := 1; i < len(tasks); i++ {
+		defRep.Links = append(defRep.Links, &definition.LinkRep{FromID: tasks[i-1].ID, ToID: tasks[i].ID})
+	}
+	def, err := definition.NewDefinition(defRep)
+	assert.Nil(t, err)
+	return def
+}
+
+// newTestInstance creates an instance of a flow whose tasks are linked in the order they are
+// specified, the name of the flow is used as the ID of the instance
+func newTestInstance(t *testing.T, name string, tasks ...*definition.TaskRep) *instance.IndependentInstance {
+	inst, err := instance.NewIndependentInstance(name, "res://flow:"+name, newTestDefinition(t, name, tasks...), nil, log.RootLogger())
+	assert.Nil(t, err)
+	return inst
+}
+
+func newSelfTestStepper(t *testing.T, ref string) *Stepper {
+	inst := newTestInstance(t, "selftest-"+ref, &definition.TaskRep{ID: "check", ActivityCfgRep: &activity.Config{Ref: ref}})
+	inst.Start(nil)
+
+	return &Stepper{inst: inst, hasWork: true}
+}
+
+func TestSelfTest(t *testing.T) {
+	assert.Nil(t, runSelfTest(newSelfTestStepper(t, "selftest-ok")))
+
+	err := runSelfTest(newSelfTestStepper(t, "selftest-fail"))
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "dependency unavailable")
+}
+
+func TestSelfTestFeatureFlagOff(t *testing.T) {
+	inst := newTestInstance(t, "selftest-flag", &definition.TaskRep{ID: "check", ActivityCfgRep: &activity.Config{Ref: "selftest-fail"}, FeatureFlag: "newCheck"})
+	inst.Start(nil)
+
+	// the failing task is skipped, the flag can't be resolved so it's off
+	assert.Nil(t, runSelfTest(&Stepper{inst: inst, hasWork: true}))
+}
@@ -0,0 +1,57 @@
+package flow
+
+import (
+	"strings"
+	"sync"
+
+	flowsupport "github.com/project-flogo/flow/support"
+)
+
+// RedactedValue replaces the value of sensitive fields
+const RedactedValue = "*****"
+
+var (
+	sensitiveMu     sync.RWMutex // protects the sensitive fields
+	sensitiveFields map[string]struct{}
+)
+
+// SetSensitiveFields sets the names of the fields whose values are redacted whenever flow
+// inputs, outputs or attributes are exposed (ex. logged), names are case-insensitive
+func SetSensitiveFields(fields []string) {
+	sf := make(map[string]struct{}, len(fields))
+	for _, field := range fields {
+		sf[strings.ToLower(strings.TrimSpace(field))] = struct{}{}
+	}
+
+	sensitiveMu.Lock()
+	sensitiveFields = sf
+	sensitiveMu.Unlock()
+}
+
+func isSensitive(field string) bool {
+	sensitiveMu.RLock()
+	defer sensitiveMu.RUnlock()
+
+	_, ok := sensitiveFields[strings.ToLower(field)]
+	return ok
+}
+
+// redact returns a copy of the values with the values of sensitive fields, including those of
+// nested objects, redacted and streamed values replaced by a placeholder
+func redact(values map[string]interface{}) map[string]interface{} {
+	if values == nil {
+		return nil
+	}
+
+	redacted := make(map[string]interface{}, len(values))
+	for name, value := range values {
+		if isSensitive(name) {
+			redacted[name] = RedactedValue
+		} else if obj, ok := value.(map[string]interface{}); ok {
+			redacted[name] = redact(obj)
+		} else {
+			redacted[name] = flowsupport.RecordableValue(value)
+		}
+	}
+	return redacted
+}
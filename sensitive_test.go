@@ -0,0 +1,25 @@
+package flow
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRedact(t *testing.T) {
+	SetSensitiveFields([]string{"password", "SSN"})
+	defer SetSensitiveFields(nil)
+
+	values := map[string]interface{}{
+		"user":     "flogo",
+		"password": "secret",
+		"customer": map[string]interface{}{"ssn": "123-45-6789", "name": "acme"},
+	}
+
+	redacted := redact(values)
+	assert.Equal(t, "flogo", redacted["user"])
+	assert.Equal(t, RedactedValue, redacted["password"])
+	assert.Equal(t, RedactedValue, redacted["customer"].(map[string]interface{})["ssn"])
+	assert.Equal(t, "acme", redacted["customer"].(map[string]interface{})["name"])
+	assert.Equal(t, "secret", values["password"])
+}
@@ -0,0 +1,103 @@
+package flow
+
+import (
+	"fmt"
+
+	"github.com/project-flogo/core/activity"
+	"github.com/project-flogo/flow/definition"
+	"github.com/project-flogo/flow/instance"
+	"github.com/project-flogo/flow/model"
+	"github.com/project-flogo/flow/state"
+)
+
+// SimResult is the result of simulating a flow
+type SimResult struct {
+	// Path is the IDs of the tasks executed, in execution order
+	Path []string `json:"path"`
+	// Status is the final status of the simulated instance, see FlowStatusKey
+	Status string `json:"status"`
+	// Outputs are the outputs of the flow when it completed
+	Outputs map[string]interface{} `json:"outputs,omitempty"`
+	// Error is the error of the flow when it failed
+	Error string `json:"error,omitempty"`
+}
+
+// SimulateFlow predicts how the flow routes for the sample inputs, without touching real systems.
+// None of the flow's activities are evaluated, instead each task sets the mock outputs for its
+// ID (or failing that, its activity ref) and links are evaluated as usual.  The simulated instance
+// isn't recorded, traced or included in the running instances.
+func SimulateFlow(flowURI string, inputs map[string]interface{}, mockOutputs map[string]map[string]interface{}) (*SimResult, error) {
+	if flowManager == nil {
+		return nil, fmt.Errorf("cannot simulate flow, flow action not initialized")
+	}
+
+	flowDef, err := flowManager.GetFlow(flowURI)
+	if err != nil {
+		return nil, err
+	}
+	if flowDef == nil {
+		return nil, fmt.Errorf("flow not found for URI: %s", flowURI)
+	}
+
+	inst, err := instance.NewIndependentInstance("simulation-"+idGenerator.NextAsString(), flowURI, flowDef, instance.NewStateInstanceRecorder(nil, state.RecordingModeOff, false), logger)
+	if err != nil {
+		return nil, err
+	}
+
+	mock := func(ctx activity.Context) (bool, error) {
+		if tp, ok := ctx.(interface{ Task() *definition.Task }); ok {
+			outputs, exists := mockOutputs[tp.Task().ID()]
+			if !exists {
+				outputs = mockOutputs[tp.Task().ActivityConfig().Ref()]
+			}
+			for name, value := range outputs {
+				if err := ctx.SetOutput(name, value); err != nil {
+					return false, err
+				}
+			}
+		}
+		return true, nil
+	}
+
+	overrides := make(map[string]instance.ActivityFunc)
+	for _, task := range flowDef.Tasks() {
+		if actCfg := task.ActivityConfig(); actCfg != nil && actCfg.Activity != nil {
+			overrides[actCfg.Ref()] = mock
+		}
+	}
+	instance.ApplyExecOptions(inst, &instance.ExecOptions{ActivityOverrides: overrides})
+
+	inst.Start(inputs)
+
+	stepCount := 0
+	hasWork := true
+	for hasWork && inst.Status() < model.FlowStatusCompleted && stepCount < maxStepCount {
+		stepCount++
+		hasWork = inst.DoStep()
+	}
+
+	result := &SimResult{}
+	for _, step := range inst.ExecutionTrace() {
+		result.Path = append(result.Path, step.TaskID)
+	}
+
+	switch inst.Status() {
+	case model.FlowStatusCompleted:
+		result.Status = FlowStatusCompleted
+		result.Outputs, err = inst.GetReturnData()
+		if err != nil {
+			result.Status = FlowStatusFailed
+			result.Error = err.Error()
+		}
+	case model.FlowStatusFailed:
+		result.Status = FlowStatusFailed
+		if err := inst.GetError(); err != nil {
+			result.Error = err.Error()
+		}
+	default:
+		result.Status = FlowStatusFailed
+		result.Error = fmt.Sprintf("flow did not complete within %d steps", maxStepCount)
+	}
+
+	return result, nil
+}
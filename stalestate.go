@@ -0,0 +1,74 @@
+package flow
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/project-flogo/flow/instance"
+)
+
+// StaleStatePolicy determines what happens when a suspended instance is resumed after its state
+// has expired (see the suspendedStateTTL runtime setting)
+type StaleStatePolicy string
+
+const (
+	// StaleStateReject fails the resume with a StateExpiredError, this is the default.  The instance
+	// is kept suspended, so it can still be aborted (see AbortSuspendedInstance).
+	StaleStateReject StaleStatePolicy = "reject"
+	// StaleStateRestart discards the expired state and starts the flow again with the inputs the
+	// instance was originally started with
+	StaleStateRestart StaleStatePolicy = "restart"
+)
+
+// suspendedStateTTL is how long the state of a suspended instance is valid for, when set
+var suspendedStateTTL time.Duration
+var staleStatePolicy = StaleStateReject
+
+// ToStaleStatePolicy converts a setting value to a StaleStatePolicy
+func ToStaleStatePolicy(policy string) (StaleStatePolicy, error) {
+	switch StaleStatePolicy(policy) {
+	case "", StaleStateReject:
+		return StaleStateReject, nil
+	case StaleStateRestart:
+		return StaleStateRestart, nil
+	default:
+		return StaleStateReject, fmt.Errorf("unsupported stale state policy [%s]", policy)
+	}
+}
+
+// StateExpiredError is returned when a suspended instance is resumed after its state expired and
+// the policy is StaleStateReject
+type StateExpiredError struct {
+	ID          string
+	SuspendedAt time.Time
+}
+
+func (e *StateExpiredError) Error() string {
+	return fmt.Sprintf("state expired, flow instance [%s] was suspended at %s", e.ID, e.SuspendedAt.Format(time.RFC3339))
+}
+
+// isStateStale indicates whether the state of the suspended instance is older than the ttl, the
+// state of an instance suspended before its suspension time was recorded never expires
+func isStateStale(inst *instance.IndependentInstance, ttl time.Duration, now time.Time) bool {
+	suspendedAt := inst.SuspendedAt()
+	return ttl > 0 && !suspendedAt.IsZero() && now.Sub(suspendedAt) > ttl
+}
+
+// checkSuspendedStateExpired returns a StateExpiredError if the state of the instance suspended
+// with the token expired and the policy rejects it.  It is checked before the instance is taken
+// from the suspension store, so that a rejected instance stays suspended.
+func checkSuspendedStateExpired(token string, now time.Time) error {
+	if suspendedStateTTL <= 0 || staleStatePolicy == StaleStateRestart {
+		return nil
+	}
+
+	inst, err := suspensionStore.Load(token)
+	if err != nil {
+		// reported when the instance is taken
+		return nil
+	}
+	if isStateStale(inst, suspendedStateTTL, now) {
+		return &StateExpiredError{ID: inst.ID(), SuspendedAt: inst.SuspendedAt()}
+	}
+	return nil
+}
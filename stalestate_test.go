@@ -0,0 +1,59 @@
+package flow
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestToStaleStatePolicy(t *testing.T) {
+	policy, err := ToStaleStatePolicy("")
+	assert.Nil(t, err)
+	assert.Equal(t, StaleStateReject, policy)
+
+	policy, err = ToStaleStatePolicy("restart")
+	assert.Nil(t, err)
+	assert.Equal(t, StaleStateRestart, policy)
+
+	_, err = ToStaleStatePolicy("resume")
+	assert.NotNil(t, err)
+}
+
+func TestIsStateStale(t *testing.T) {
+	inst := newTestInstance(t, "stale")
+
+	// not suspended
+	assert.False(t, isStateStale(inst, time.Minute, time.Now()))
+
+	inst.Suspend("token-1")
+	assert.False(t, isStateStale(inst, time.Minute, time.Now()))
+	assert.True(t, isStateStale(inst, time.Minute, time.Now().Add(2*time.Minute)))
+	// the state never expires without a ttl
+	assert.False(t, isStateStale(inst, 0, time.Now().Add(2*time.Minute)))
+
+	err := &StateExpiredError{ID: "stale", SuspendedAt: inst.SuspendedAt()}
+	assert.Contains(t, err.Error(), "state expired")
+}
+
+func TestCheckSuspendedStateExpired(t *testing.T) {
+	defer SetSuspensionStore(nil)
+	defer func() { suspendedStateTTL = 0 }()
+
+	inst := newTestInstance(t, "expired")
+	inst.Suspend("token-2")
+	assert.Nil(t, suspendInstance("token-2", inst))
+
+	assert.Nil(t, checkSuspendedStateExpired("token-2", time.Now().Add(2*time.Minute)))
+
+	suspendedStateTTL = time.Minute
+	assert.Nil(t, checkSuspendedStateExpired("token-2", time.Now()))
+	assert.Nil(t, checkSuspendedStateExpired("unknown", time.Now()))
+
+	err := checkSuspendedStateExpired("token-2", time.Now().Add(2*time.Minute))
+	assert.IsType(t, &StateExpiredError{}, err)
+
+	// the rejected instance is still suspended
+	_, err = suspensionStore.Load("token-2")
+	assert.Nil(t, err)
+}
@@ -0,0 +1,171 @@
+package state
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/project-flogo/core/support/log"
+)
+
+// BackpressurePolicy determines what the async recorder does when its buffer is full
+type BackpressurePolicy string
+
+const (
+	// BackpressureBlock blocks the caller until there is room in the buffer
+	BackpressureBlock BackpressurePolicy = "block"
+	// BackpressureDropOldest drops the oldest buffered step to make room
+	BackpressureDropOldest BackpressurePolicy = "dropOldest"
+
+	// DefaultAsyncBufferSize is the buffer size used when none is specified
+	DefaultAsyncBufferSize = 1024
+)
+
+// ToBackpressurePolicy converts the specified value to a BackpressurePolicy
+func ToBackpressurePolicy(policy string) (BackpressurePolicy, error) {
+	switch {
+	case policy == "", strings.EqualFold(policy, string(BackpressureBlock)):
+		return BackpressureBlock, nil
+	case strings.EqualFold(policy, string(BackpressureDropOldest)):
+		return BackpressureDropOldest, nil
+	default:
+		return BackpressureBlock, fmt.Errorf("unsupported backpressure policy [%s]", policy)
+	}
+}
+
+// NewAsyncRecorder wraps the specified Recorder so that steps and snapshots are recorded
+// on a background goroutine.  RecordStart and RecordDone are passed through synchronously,
+// RecordDone first waiting for all buffered steps and snapshots to be flushed.
+func NewAsyncRecorder(recorder Recorder, bufferSize int, policy BackpressurePolicy, logger log.Logger) Recorder {
+	if bufferSize <= 0 {
+		bufferSize = DefaultAsyncBufferSize
+	}
+
+	r := &asyncRecorder{
+		recorder: recorder,
+		policy:   policy,
+		logger:   logger,
+		queue:    make(chan interface{}, bufferSize),
+	}
+	r.cond = sync.NewCond(&r.mu)
+
+	go r.process()
+
+	return r
+}
+
+type asyncRecorder struct {
+	recorder Recorder
+	policy   BackpressurePolicy
+	logger   log.Logger
+	queue    chan interface{}
+
+	mu        sync.Mutex
+	cond      *sync.Cond
+	enqueued  uint64
+	completed uint64
+}
+
+func (r *asyncRecorder) RecordStart(state *FlowState) error {
+	return r.recorder.RecordStart(state)
+}
+
+func (r *asyncRecorder) RecordSnapshot(snapshot *Snapshot) error {
+	r.enqueue(snapshot)
+	return nil
+}
+
+func (r *asyncRecorder) RecordStep(step *Step) error {
+	r.enqueue(step)
+	return nil
+}
+
+func (r *asyncRecorder) RecordDone(state *FlowState) error {
+	r.flush()
+	return r.recorder.RecordDone(state)
+}
+
+func (r *asyncRecorder) Ping() error {
+	return Ping(r.recorder)
+}
+
+func (r *asyncRecorder) GetSteps(flowID string) ([]*Step, error) {
+	r.flush()
+	return GetSteps(r.recorder, flowID)
+}
+
+func (r *asyncRecorder) GetSnapshot(flowID string) (*Snapshot, error) {
+	r.flush()
+	return GetSnapshot(r.recorder, flowID)
+}
+
+func (r *asyncRecorder) GetCheckpoint(flowID, name string) (*Snapshot, error) {
+	r.flush()
+	return GetCheckpoint(r.recorder, flowID, name)
+}
+
+func (r *asyncRecorder) RecordCancel(marker *CancelMarker) error {
+	return RecordCancel(r.recorder, marker)
+}
+
+func (r *asyncRecorder) GetCancel(flowID string) (*CancelMarker, error) {
+	return GetCancel(r.recorder, flowID)
+}
+
+func (r *asyncRecorder) enqueue(item interface{}) {
+	r.mu.Lock()
+	r.enqueued++
+	r.mu.Unlock()
+
+	if r.policy != BackpressureDropOldest {
+		r.queue <- item
+		return
+	}
+
+	for {
+		select {
+		case r.queue <- item:
+			return
+		default:
+			select {
+			case <-r.queue:
+				r.logger.Warn("State recorder buffer full, dropping oldest buffered step")
+				r.complete()
+			default:
+			}
+		}
+	}
+}
+
+// flush blocks until everything enqueued before the call has been recorded or dropped
+func (r *asyncRecorder) flush() {
+	r.mu.Lock()
+	target := r.enqueued
+	for r.completed < target {
+		r.cond.Wait()
+	}
+	r.mu.Unlock()
+}
+
+func (r *asyncRecorder) complete() {
+	r.mu.Lock()
+	r.completed++
+	r.cond.Broadcast()
+	r.mu.Unlock()
+}
+
+func (r *asyncRecorder) process() {
+	for item := range r.queue {
+		switch t := item.(type) {
+		case *Step:
+			if err := r.recorder.RecordStep(t); err != nil {
+				r.logger.Warnf("unable to record step for instance [%s]: %v", t.FlowId, err)
+			}
+		case *Snapshot:
+			if err := r.recorder.RecordSnapshot(t); err != nil {
+				r.logger.Warnf("unable to record snapshot for instance [%s]: %v", t.Id, err)
+			}
+		}
+		r.complete()
+	}
+}
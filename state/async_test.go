@@ -0,0 +1,49 @@
+package state
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/project-flogo/core/support/log"
+	"github.com/stretchr/testify/assert"
+)
+
+type slowRecorder struct {
+	testRecorder
+	mu    sync.Mutex
+	steps []int
+}
+
+func (r *slowRecorder) RecordStep(step *Step) error {
+	time.Sleep(time.Millisecond)
+	r.mu.Lock()
+	r.steps = append(r.steps, step.Id)
+	r.mu.Unlock()
+	return nil
+}
+
+func TestAsyncRecorderFlushOnDone(t *testing.T) {
+	sr := &slowRecorder{}
+	r := NewAsyncRecorder(sr, 2, BackpressureBlock, log.RootLogger())
+
+	for i := 1; i <= 5; i++ {
+		assert.Nil(t, r.RecordStep(&Step{Id: i}))
+	}
+	assert.Nil(t, r.RecordDone(&FlowState{}))
+
+	sr.mu.Lock()
+	defer sr.mu.Unlock()
+	assert.Equal(t, []int{1, 2, 3, 4, 5}, sr.steps)
+}
+
+func TestToBackpressurePolicy(t *testing.T) {
+	p, err := ToBackpressurePolicy("")
+	assert.Nil(t, err)
+	assert.Equal(t, BackpressureBlock, p)
+	p, err = ToBackpressurePolicy("DropOldest")
+	assert.Nil(t, err)
+	assert.Equal(t, BackpressureDropOldest, p)
+	_, err = ToBackpressurePolicy("other")
+	assert.NotNil(t, err)
+}
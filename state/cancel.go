@@ -0,0 +1,40 @@
+package state
+
+import (
+	"fmt"
+	"time"
+)
+
+// CancelMarker is recorded for an instance whose cancellation was requested, it is checked when
+// the instance is resumed so a cancel that raced with a suspension still takes effect
+type CancelMarker struct {
+	FlowID      string    `json:"flowId"`
+	RequestedAt time.Time `json:"requestedAt"`
+}
+
+// CancelRecorder is optionally implemented by a Recorder that can record cancel markers
+type CancelRecorder interface {
+	// RecordCancel records the cancel marker of an instance
+	RecordCancel(marker *CancelMarker) error
+	// GetCancel returns the cancel marker recorded for the instance, nil if there is none
+	GetCancel(flowID string) (*CancelMarker, error)
+}
+
+// RecordCancel records the cancel marker with the specified Recorder
+func RecordCancel(recorder Recorder, marker *CancelMarker) error {
+	cr, ok := recorder.(CancelRecorder)
+	if !ok {
+		return fmt.Errorf("state recorder does not support recording cancellations")
+	}
+	return cr.RecordCancel(marker)
+}
+
+// GetCancel returns the cancel marker recorded for the instance by the specified Recorder, nil is
+// returned if there is none or the Recorder doesn't record cancellations
+func GetCancel(recorder Recorder, flowID string) (*CancelMarker, error) {
+	cr, ok := recorder.(CancelRecorder)
+	if !ok {
+		return nil, nil
+	}
+	return cr.GetCancel(flowID)
+}
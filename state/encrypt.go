@@ -0,0 +1,324 @@
+package state
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/project-flogo/flow/state/change"
+)
+
+// EncryptedPrefix prefixes the recorded value of an encrypted attribute, it is followed by the
+// base64 encoding of the encrypted JSON encoding of the value
+const EncryptedPrefix = "enc:"
+
+// AttributeEncryptor encrypts and decrypts the values of sensitive attributes recorded to durable
+// storage.  Managing the keys is up to the implementation, it must be safe for concurrent use.
+type AttributeEncryptor interface {
+	Encrypt(plaintext []byte) ([]byte, error)
+	Decrypt(ciphertext []byte) ([]byte, error)
+}
+
+// NewAESGCMEncryptor creates an AttributeEncryptor that uses AES-GCM with the specified key, which
+// must be 16, 24 or 32 bytes long.  A random nonce is generated for each value and prepended to
+// its ciphertext.
+func NewAESGCMEncryptor(key []byte) (AttributeEncryptor, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &aesGCMEncryptor{aead: aead}, nil
+}
+
+type aesGCMEncryptor struct {
+	aead cipher.AEAD
+}
+
+func (e *aesGCMEncryptor) Encrypt(plaintext []byte) ([]byte, error) {
+	nonce := make([]byte, e.aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+	return e.aead.Seal(nonce, nonce, plaintext, nil), nil
+}
+
+func (e *aesGCMEncryptor) Decrypt(ciphertext []byte) ([]byte, error) {
+	size := e.aead.NonceSize()
+	if len(ciphertext) < size {
+		return nil, fmt.Errorf("ciphertext too short")
+	}
+	return e.aead.Open(nil, ciphertext[:size], ciphertext[size:], nil)
+}
+
+// NewEncryptingRecorder wraps the specified Recorder, encrypting the values of the attributes at
+// the specified paths before they are recorded and decrypting them when the steps or snapshots are
+// read back (see HistoryReader, SnapshotReader and CheckpointReader), ex. to rerun an instance.  The
+// paths are also applied to the task inputs and the return data recorded in the steps.  A path is
+// the name of an attribute, optionally followed by the dot separated names of the fields of nested
+// objects, ex. "customer.ssn".
+func NewEncryptingRecorder(recorder Recorder, encryptor AttributeEncryptor, paths []string) Recorder {
+	r := &encryptingRecorder{recorder: recorder, encryptor: encryptor}
+	for _, path := range paths {
+		if path != "" {
+			r.paths = append(r.paths, strings.Split(path, "."))
+		}
+	}
+	return r
+}
+
+type encryptingRecorder struct {
+	recorder  Recorder
+	encryptor AttributeEncryptor
+	paths     [][]string
+}
+
+func (r *encryptingRecorder) RecordStart(state *FlowState) error {
+	return r.recorder.RecordStart(state)
+}
+
+func (r *encryptingRecorder) RecordSnapshot(snapshot *Snapshot) error {
+	encrypted, err := r.snapshot(snapshot, r.encrypt)
+	if err != nil {
+		return err
+	}
+	return r.recorder.RecordSnapshot(encrypted)
+}
+
+func (r *encryptingRecorder) RecordStep(step *Step) error {
+	encrypted, err := r.step(step, r.encrypt)
+	if err != nil {
+		return err
+	}
+	return r.recorder.RecordStep(encrypted)
+}
+
+func (r *encryptingRecorder) RecordDone(state *FlowState) error {
+	return r.recorder.RecordDone(state)
+}
+
+func (r *encryptingRecorder) Ping() error {
+	return Ping(r.recorder)
+}
+
+// GetSteps reads the steps from the wrapped Recorder, decrypting the values of the attributes
+func (r *encryptingRecorder) GetSteps(flowID string) ([]*Step, error) {
+	steps, err := GetSteps(r.recorder, flowID)
+	if err != nil {
+		return nil, err
+	}
+
+	decrypted := make([]*Step, len(steps))
+	for i, step := range steps {
+		decrypted[i], err = r.step(step, r.decrypt)
+		if err != nil {
+			return nil, fmt.Errorf("unable to decrypt attributes of step %d: %s", step.Id, err.Error())
+		}
+	}
+	return decrypted, nil
+}
+
+// GetSnapshot reads the snapshot from the wrapped Recorder, decrypting the values of the attributes
+func (r *encryptingRecorder) GetSnapshot(flowID string) (*Snapshot, error) {
+	snapshot, err := GetSnapshot(r.recorder, flowID)
+	if err != nil {
+		return nil, err
+	}
+
+	decrypted, err := r.snapshot(snapshot, r.decrypt)
+	if err != nil {
+		return nil, fmt.Errorf("unable to decrypt attributes of snapshot: %s", err.Error())
+	}
+	return decrypted, nil
+}
+
+// GetCheckpoint reads the checkpoint from the wrapped Recorder, decrypting the values of the
+// attributes
+func (r *encryptingRecorder) GetCheckpoint(flowID, name string) (*Snapshot, error) {
+	snapshot, err := GetCheckpoint(r.recorder, flowID, name)
+	if err != nil {
+		return nil, err
+	}
+
+	decrypted, err := r.snapshot(snapshot, r.decrypt)
+	if err != nil {
+		return nil, fmt.Errorf("unable to decrypt attributes of checkpoint [%s]: %s", name, err.Error())
+	}
+	return decrypted, nil
+}
+
+func (r *encryptingRecorder) RecordCancel(marker *CancelMarker) error {
+	return RecordCancel(r.recorder, marker)
+}
+
+func (r *encryptingRecorder) GetCancel(flowID string) (*CancelMarker, error) {
+	return GetCancel(r.recorder, flowID)
+}
+
+// snapshot returns a copy of the snapshot with f applied to the values of the attributes
+func (r *encryptingRecorder) snapshot(snapshot *Snapshot, f func(interface{}) (interface{}, error)) (*Snapshot, error) {
+	copied := *snapshot
+	if snapshot.SnapshotBase != nil {
+		base, err := r.snapshotBase(snapshot.SnapshotBase, f)
+		if err != nil {
+			return nil, err
+		}
+		copied.SnapshotBase = base
+	}
+
+	if len(snapshot.Subflows) > 0 {
+		copied.Subflows = make([]*Subflow, len(snapshot.Subflows))
+		for i, subflow := range snapshot.Subflows {
+			sf := *subflow
+			if subflow.SnapshotBase != nil {
+				base, err := r.snapshotBase(subflow.SnapshotBase, f)
+				if err != nil {
+					return nil, err
+				}
+				sf.SnapshotBase = base
+			}
+			copied.Subflows[i] = &sf
+		}
+	}
+	return &copied, nil
+}
+
+func (r *encryptingRecorder) snapshotBase(base *SnapshotBase, f func(interface{}) (interface{}, error)) (*SnapshotBase, error) {
+	copied := *base
+	attrs, err := r.apply(base.Attrs, f)
+	if err != nil {
+		return nil, err
+	}
+	copied.Attrs = attrs
+	return &copied, nil
+}
+
+// step returns a copy of the step with f applied to the values of the attributes, including
+// those of the task inputs and the return data
+func (r *encryptingRecorder) step(step *Step, f func(interface{}) (interface{}, error)) (*Step, error) {
+	copied := *step
+	if len(step.FlowChanges) == 0 {
+		return &copied, nil
+	}
+
+	var err error
+	copied.FlowChanges = make(map[int]*change.Flow, len(step.FlowChanges))
+	for id, flowChg := range step.FlowChanges {
+		if flowChg == nil {
+			copied.FlowChanges[id] = nil
+			continue
+		}
+
+		chg := *flowChg
+		if chg.Attrs, err = r.apply(flowChg.Attrs, f); err != nil {
+			return nil, err
+		}
+		if chg.ReturnData, err = r.apply(flowChg.ReturnData, f); err != nil {
+			return nil, err
+		}
+		if len(flowChg.Tasks) > 0 {
+			chg.Tasks = make(map[string]*change.Task, len(flowChg.Tasks))
+			for taskID, taskChg := range flowChg.Tasks {
+				if taskChg == nil {
+					chg.Tasks[taskID] = nil
+					continue
+				}
+				task := *taskChg
+				if task.Input, err = r.apply(taskChg.Input, f); err != nil {
+					return nil, err
+				}
+				chg.Tasks[taskID] = &task
+			}
+		}
+		copied.FlowChanges[id] = &chg
+	}
+	return &copied, nil
+}
+
+// apply returns a copy of the attributes with f applied to the values at the paths, the maps along
+// a path are copied so the attributes themselves are left untouched
+func (r *encryptingRecorder) apply(attrs map[string]interface{}, f func(interface{}) (interface{}, error)) (map[string]interface{}, error) {
+	var err error
+	for _, path := range r.paths {
+		attrs, err = applyPath(attrs, path, f)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return attrs, nil
+}
+
+func applyPath(values map[string]interface{}, path []string, f func(interface{}) (interface{}, error)) (map[string]interface{}, error) {
+	value, ok := values[path[0]]
+	if !ok || value == nil {
+		return values, nil
+	}
+
+	if len(path) > 1 {
+		obj, ok := value.(map[string]interface{})
+		if !ok {
+			return values, nil
+		}
+		newObj, err := applyPath(obj, path[1:], f)
+		if err != nil {
+			return nil, err
+		}
+		value = newObj
+	} else {
+		var err error
+		value, err = f(value)
+		if err != nil {
+			return nil, fmt.Errorf("attribute '%s': %s", path[0], err.Error())
+		}
+	}
+
+	copied := make(map[string]interface{}, len(values))
+	for name, v := range values {
+		copied[name] = v
+	}
+	copied[path[0]] = value
+	return copied, nil
+}
+
+func (r *encryptingRecorder) encrypt(value interface{}) (interface{}, error) {
+	plaintext, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	ciphertext, err := r.encryptor.Encrypt(plaintext)
+	if err != nil {
+		return nil, err
+	}
+	return EncryptedPrefix + base64.StdEncoding.EncodeToString(ciphertext), nil
+}
+
+// decrypt decrypts the value, values that weren't encrypted are returned as is
+func (r *encryptingRecorder) decrypt(value interface{}) (interface{}, error) {
+	s, ok := value.(string)
+	if !ok || !strings.HasPrefix(s, EncryptedPrefix) {
+		return value, nil
+	}
+
+	ciphertext, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(s, EncryptedPrefix))
+	if err != nil {
+		return nil, err
+	}
+	plaintext, err := r.encryptor.Decrypt(ciphertext)
+	if err != nil {
+		return nil, err
+	}
+
+	var decrypted interface{}
+	if err := json.Unmarshal(plaintext, &decrypted); err != nil {
+		return nil, err
+	}
+	return decrypted, nil
+}
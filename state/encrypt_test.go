@@ -0,0 +1,115 @@
+package state
+
+import (
+	"testing"
+
+	"github.com/project-flogo/flow/state/change"
+	"github.com/stretchr/testify/assert"
+)
+
+type storingRecorder struct {
+	historyRecorder
+	snapshot *Snapshot
+}
+
+func (r *storingRecorder) RecordSnapshot(snapshot *Snapshot) error {
+	r.snapshot = snapshot
+	return nil
+}
+
+func (r *storingRecorder) GetSnapshot(flowID string) (*Snapshot, error) {
+	if r.snapshot == nil {
+		return nil, ErrInstanceNotFound
+	}
+	return r.snapshot, nil
+}
+
+func (r *storingRecorder) GetCheckpoint(flowID, name string) (*Snapshot, error) {
+	if r.snapshot == nil || r.snapshot.Checkpoint != name {
+		return nil, ErrInstanceNotFound
+	}
+	return r.snapshot, nil
+}
+
+func (r *storingRecorder) RecordStep(step *Step) error {
+	r.steps[step.FlowId] = append(r.steps[step.FlowId], step)
+	return nil
+}
+
+func TestAESGCMEncryptor(t *testing.T) {
+	_, err := NewAESGCMEncryptor([]byte("short"))
+	assert.NotNil(t, err)
+
+	encryptor, err := NewAESGCMEncryptor([]byte("0123456789abcdef"))
+	assert.Nil(t, err)
+
+	ciphertext, err := encryptor.Encrypt([]byte("secret"))
+	assert.Nil(t, err)
+	assert.NotContains(t, string(ciphertext), "secret")
+
+	plaintext, err := encryptor.Decrypt(ciphertext)
+	assert.Nil(t, err)
+	assert.Equal(t, "secret", string(plaintext))
+
+	ciphertext[len(ciphertext)-1] ^= 1
+	_, err = encryptor.Decrypt(ciphertext)
+	assert.NotNil(t, err)
+}
+
+func TestEncryptingRecorder(t *testing.T) {
+	encryptor, err := NewAESGCMEncryptor([]byte("0123456789abcdef"))
+	assert.Nil(t, err)
+
+	store := &storingRecorder{historyRecorder: historyRecorder{steps: make(map[string][]*Step)}}
+	recorder := NewEncryptingRecorder(store, encryptor, []string{"ssn", "customer.card"})
+
+	attrs := map[string]interface{}{
+		"ssn":      "123-45-6789",
+		"orderId":  "1",
+		"customer": map[string]interface{}{"name": "joe", "card": "4111"},
+	}
+
+	assert.Nil(t, recorder.RecordSnapshot(&Snapshot{Id: "1", SnapshotBase: &SnapshotBase{Attrs: attrs}, Checkpoint: "paid"}))
+	recorded := store.snapshot.Attrs
+	assert.Contains(t, recorded["ssn"], EncryptedPrefix)
+	assert.Equal(t, "1", recorded["orderId"])
+	assert.Contains(t, recorded["customer"].(map[string]interface{})["card"], EncryptedPrefix)
+	assert.Equal(t, "joe", recorded["customer"].(map[string]interface{})["name"])
+
+	// the attributes of the instance are left untouched
+	assert.Equal(t, "123-45-6789", attrs["ssn"])
+	assert.Equal(t, "4111", attrs["customer"].(map[string]interface{})["card"])
+
+	snapshot, err := GetSnapshot(recorder, "1")
+	assert.Nil(t, err)
+	assert.Equal(t, "123-45-6789", snapshot.Attrs["ssn"])
+	assert.Equal(t, "4111", snapshot.Attrs["customer"].(map[string]interface{})["card"])
+	assert.Contains(t, store.snapshot.Attrs["ssn"], EncryptedPrefix)
+
+	snapshot, err = GetCheckpoint(recorder, "1", "paid")
+	assert.Nil(t, err)
+	assert.Equal(t, "123-45-6789", snapshot.Attrs["ssn"])
+	_, err = GetCheckpoint(recorder, "1", "shipped")
+	assert.Equal(t, ErrInstanceNotFound, err)
+
+	assert.Nil(t, recorder.RecordStep(&Step{Id: 1, FlowId: "1", FlowChanges: map[int]*change.Flow{0: {
+		Attrs:      attrs,
+		Tasks:      map[string]*change.Task{"lookup": {Input: map[string]interface{}{"ssn": "123-45-6789"}}},
+		ReturnData: map[string]interface{}{"ssn": "123-45-6789"},
+	}}}))
+	recordedChg := store.steps["1"][0].FlowChanges[0]
+	assert.Contains(t, recordedChg.Attrs["ssn"], EncryptedPrefix)
+	assert.Contains(t, recordedChg.Tasks["lookup"].Input["ssn"], EncryptedPrefix)
+	assert.Contains(t, recordedChg.ReturnData["ssn"], EncryptedPrefix)
+
+	steps, err := GetSteps(recorder, "1")
+	assert.Nil(t, err)
+	flowChg := steps[0].FlowChanges[0]
+	assert.Equal(t, "123-45-6789", flowChg.Attrs["ssn"])
+	assert.Equal(t, "4111", flowChg.Attrs["customer"].(map[string]interface{})["card"])
+	assert.Equal(t, "123-45-6789", flowChg.Tasks["lookup"].Input["ssn"])
+	assert.Equal(t, "123-45-6789", flowChg.ReturnData["ssn"])
+
+	// the recorded steps are left encrypted
+	assert.Contains(t, store.steps["1"][0].FlowChanges[0].Attrs["ssn"], EncryptedPrefix)
+}
@@ -13,6 +13,7 @@ type FlowState struct {
 	FlowInstanceId string `json:"flow_instance_id"`
 	FlowStats      string `json:"flow_stats"`
 	//FlowInputs     map[string]interface{} `json:"flow_inputs"`
-	StartTime time.Time `json:"start_time"`
-	EndTime   time.Time `json:"end_time"`
+	StartTime time.Time         `json:"start_time"`
+	EndTime   time.Time         `json:"end_time"`
+	Labels    map[string]string `json:"labels,omitempty"`
 }
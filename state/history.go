@@ -0,0 +1,71 @@
+package state
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// ErrInstanceNotFound is returned by a HistoryReader when it has no recorded state for an instance
+var ErrInstanceNotFound = errors.New("instance not found")
+
+// HistoryReader is optionally implemented by a Recorder that can read back the steps it recorded
+type HistoryReader interface {
+	// GetSteps returns the recorded steps of the instance, ErrInstanceNotFound is returned
+	// if the instance was never recorded
+	GetSteps(flowID string) ([]*Step, error)
+}
+
+// GetSteps returns the steps of the instance recorded by the specified Recorder, ordered by step ID
+func GetSteps(recorder Recorder, flowID string) ([]*Step, error) {
+	reader, ok := recorder.(HistoryReader)
+	if !ok {
+		return nil, fmt.Errorf("state recorder does not support reading instance history")
+	}
+
+	steps, err := reader.GetSteps(flowID)
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(steps, func(i, j int) bool {
+		return steps[i].Id < steps[j].Id
+	})
+
+	return steps, nil
+}
+
+// SnapshotReader is optionally implemented by a Recorder that can read back the latest snapshot it
+// recorded for an instance
+type SnapshotReader interface {
+	// GetSnapshot returns the latest recorded snapshot of the instance, ErrInstanceNotFound is
+	// returned if no snapshot of the instance was recorded
+	GetSnapshot(flowID string) (*Snapshot, error)
+}
+
+// GetSnapshot returns the latest snapshot of the instance recorded by the specified Recorder
+func GetSnapshot(recorder Recorder, flowID string) (*Snapshot, error) {
+	reader, ok := recorder.(SnapshotReader)
+	if !ok {
+		return nil, fmt.Errorf("state recorder does not support reading snapshots")
+	}
+	return reader.GetSnapshot(flowID)
+}
+
+// CheckpointReader is optionally implemented by a Recorder that can read back the snapshots it
+// recorded for the named checkpoints of an instance (see Snapshot.Checkpoint)
+type CheckpointReader interface {
+	// GetCheckpoint returns the latest snapshot of the instance recorded for the checkpoint,
+	// ErrInstanceNotFound is returned if none was recorded
+	GetCheckpoint(flowID, name string) (*Snapshot, error)
+}
+
+// GetCheckpoint returns the latest snapshot of the instance recorded for the checkpoint by the
+// specified Recorder
+func GetCheckpoint(recorder Recorder, flowID, name string) (*Snapshot, error) {
+	reader, ok := recorder.(CheckpointReader)
+	if !ok {
+		return nil, fmt.Errorf("state recorder does not support reading checkpoints")
+	}
+	return reader.GetCheckpoint(flowID, name)
+}
@@ -0,0 +1,81 @@
+package state
+
+import (
+	"time"
+
+	"github.com/project-flogo/flow/support/metrics"
+)
+
+// NewInstrumentedRecorder wraps the specified Recorder, timing each call and
+// counting the calls that fail
+func NewInstrumentedRecorder(recorder Recorder) Recorder {
+	return &instrumentedRecorder{recorder: recorder}
+}
+
+type instrumentedRecorder struct {
+	recorder Recorder
+}
+
+func (r *instrumentedRecorder) RecordStart(state *FlowState) error {
+	start := time.Now()
+	err := r.recorder.RecordStart(state)
+	record("RecordStart", start, err)
+	return err
+}
+
+func (r *instrumentedRecorder) RecordSnapshot(snapshot *Snapshot) error {
+	start := time.Now()
+	err := r.recorder.RecordSnapshot(snapshot)
+	record("RecordSnapshot", start, err)
+	return err
+}
+
+func (r *instrumentedRecorder) RecordStep(step *Step) error {
+	start := time.Now()
+	err := r.recorder.RecordStep(step)
+	record("RecordStep", start, err)
+	return err
+}
+
+func (r *instrumentedRecorder) RecordDone(state *FlowState) error {
+	start := time.Now()
+	err := r.recorder.RecordDone(state)
+	record("RecordDone", start, err)
+	return err
+}
+
+func (r *instrumentedRecorder) Ping() error {
+	return Ping(r.recorder)
+}
+
+func (r *instrumentedRecorder) GetSteps(flowID string) ([]*Step, error) {
+	return GetSteps(r.recorder, flowID)
+}
+
+func (r *instrumentedRecorder) GetSnapshot(flowID string) (*Snapshot, error) {
+	return GetSnapshot(r.recorder, flowID)
+}
+
+func (r *instrumentedRecorder) GetCheckpoint(flowID, name string) (*Snapshot, error) {
+	return GetCheckpoint(r.recorder, flowID, name)
+}
+
+func (r *instrumentedRecorder) RecordCancel(marker *CancelMarker) error {
+	return RecordCancel(r.recorder, marker)
+}
+
+func (r *instrumentedRecorder) GetCancel(flowID string) (*CancelMarker, error) {
+	return GetCancel(r.recorder, flowID)
+}
+
+func record(op string, start time.Time, err error) {
+	if !metrics.Enabled() {
+		return
+	}
+
+	labels := map[string]string{"op": op}
+	metrics.Timing(metrics.RecorderLatency, time.Since(start), labels)
+	if err != nil {
+		metrics.Count(metrics.RecorderErrors, 1, labels)
+	}
+}
@@ -0,0 +1,93 @@
+package state
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/project-flogo/flow/support/metrics"
+	"github.com/stretchr/testify/assert"
+)
+
+type testRecorder struct {
+	err error
+}
+
+func (r *testRecorder) RecordStart(state *FlowState) error      { return r.err }
+func (r *testRecorder) RecordSnapshot(snapshot *Snapshot) error { return r.err }
+func (r *testRecorder) RecordStep(step *Step) error             { return r.err }
+func (r *testRecorder) RecordDone(state *FlowState) error       { return r.err }
+
+type testCollector struct {
+	timings map[string]int
+	counts  map[string]int64
+}
+
+func (c *testCollector) Timing(name string, duration time.Duration, labels map[string]string) {
+	c.timings[labels["op"]]++
+}
+
+func (c *testCollector) Count(name string, delta int64, labels map[string]string) {
+	c.counts[labels["op"]] += delta
+}
+
+func TestInstrumentedRecorder(t *testing.T) {
+	c := &testCollector{timings: make(map[string]int), counts: make(map[string]int64)}
+	metrics.SetCollector(c)
+	defer metrics.SetCollector(nil)
+
+	r := NewInstrumentedRecorder(&testRecorder{})
+	assert.Nil(t, r.RecordStart(&FlowState{}))
+	assert.Nil(t, r.RecordStep(&Step{}))
+	assert.Equal(t, 1, c.timings["RecordStart"])
+	assert.Equal(t, 1, c.timings["RecordStep"])
+	assert.Equal(t, int64(0), c.counts["RecordStart"])
+
+	r = NewInstrumentedRecorder(&testRecorder{err: errors.New("unavailable")})
+	assert.NotNil(t, r.RecordDone(&FlowState{}))
+	assert.Equal(t, 1, c.timings["RecordDone"])
+	assert.Equal(t, int64(1), c.counts["RecordDone"])
+}
+
+type pingRecorder struct {
+	testRecorder
+}
+
+func (r *pingRecorder) Ping() error {
+	return r.err
+}
+
+func TestPing(t *testing.T) {
+	assert.Nil(t, Ping(&testRecorder{}))
+
+	recorder := NewInstrumentedRecorder(&pingRecorder{testRecorder{err: errors.New("unreachable")}})
+	assert.NotNil(t, Ping(recorder))
+}
+
+type historyRecorder struct {
+	testRecorder
+	steps map[string][]*Step
+}
+
+func (r *historyRecorder) GetSteps(flowID string) ([]*Step, error) {
+	steps, ok := r.steps[flowID]
+	if !ok {
+		return nil, ErrInstanceNotFound
+	}
+	return steps, nil
+}
+
+func TestGetSteps(t *testing.T) {
+	_, err := GetSteps(&testRecorder{}, "1")
+	assert.NotNil(t, err)
+
+	recorder := NewInstrumentedRecorder(&historyRecorder{steps: map[string][]*Step{"1": {{Id: 2}, {Id: 1}}}})
+
+	steps, err := GetSteps(recorder, "1")
+	assert.Nil(t, err)
+	assert.Equal(t, 1, steps[0].Id)
+	assert.Equal(t, 2, steps[1].Id)
+
+	_, err = GetSteps(recorder, "2")
+	assert.Equal(t, ErrInstanceNotFound, err)
+}
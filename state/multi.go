@@ -0,0 +1,108 @@
+package state
+
+import (
+	"fmt"
+	"strings"
+)
+
+// NewMultiRecorder creates a Recorder that records to all of the specified Recorders, ex. a fast
+// cache and durable storage.  Every Recorder is called, even if an earlier one fails, and their
+// errors are aggregated.
+func NewMultiRecorder(recorders ...Recorder) Recorder {
+	return &multiRecorder{recorders: recorders}
+}
+
+type multiRecorder struct {
+	recorders []Recorder
+}
+
+func (r *multiRecorder) RecordStart(state *FlowState) error {
+	return r.each(func(recorder Recorder) error { return recorder.RecordStart(state) })
+}
+
+func (r *multiRecorder) RecordSnapshot(snapshot *Snapshot) error {
+	return r.each(func(recorder Recorder) error { return recorder.RecordSnapshot(snapshot) })
+}
+
+func (r *multiRecorder) RecordStep(step *Step) error {
+	return r.each(func(recorder Recorder) error { return recorder.RecordStep(step) })
+}
+
+func (r *multiRecorder) RecordDone(state *FlowState) error {
+	return r.each(func(recorder Recorder) error { return recorder.RecordDone(state) })
+}
+
+func (r *multiRecorder) Ping() error {
+	return r.each(Ping)
+}
+
+// GetSteps reads the steps from the first Recorder that implements HistoryReader
+func (r *multiRecorder) GetSteps(flowID string) ([]*Step, error) {
+	for _, recorder := range r.recorders {
+		if _, ok := recorder.(HistoryReader); ok {
+			return GetSteps(recorder, flowID)
+		}
+	}
+	return nil, fmt.Errorf("state recorder does not support reading instance history")
+}
+
+// GetSnapshot reads the snapshot from the first Recorder that implements SnapshotReader
+func (r *multiRecorder) GetSnapshot(flowID string) (*Snapshot, error) {
+	for _, recorder := range r.recorders {
+		if _, ok := recorder.(SnapshotReader); ok {
+			return GetSnapshot(recorder, flowID)
+		}
+	}
+	return nil, fmt.Errorf("state recorder does not support reading snapshots")
+}
+
+// GetCheckpoint reads the checkpoint from the first Recorder that implements CheckpointReader
+func (r *multiRecorder) GetCheckpoint(flowID, name string) (*Snapshot, error) {
+	for _, recorder := range r.recorders {
+		if _, ok := recorder.(CheckpointReader); ok {
+			return GetCheckpoint(recorder, flowID, name)
+		}
+	}
+	return nil, fmt.Errorf("state recorder does not support reading checkpoints")
+}
+
+// RecordCancel records the cancel marker to every Recorder that implements CancelRecorder
+func (r *multiRecorder) RecordCancel(marker *CancelMarker) error {
+	recorded := false
+	err := r.each(func(recorder Recorder) error {
+		if _, ok := recorder.(CancelRecorder); !ok {
+			return nil
+		}
+		recorded = true
+		return RecordCancel(recorder, marker)
+	})
+	if err == nil && !recorded {
+		return fmt.Errorf("state recorder does not support recording cancellations")
+	}
+	return err
+}
+
+// GetCancel returns the first cancel marker recorded for the instance by any of the Recorders
+func (r *multiRecorder) GetCancel(flowID string) (*CancelMarker, error) {
+	for _, recorder := range r.recorders {
+		marker, err := GetCancel(recorder, flowID)
+		if err != nil || marker != nil {
+			return marker, err
+		}
+	}
+	return nil, nil
+}
+
+func (r *multiRecorder) each(f func(recorder Recorder) error) error {
+	var errs []string
+	for _, recorder := range r.recorders {
+		if err := f(recorder); err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("%d of %d state recorders failed: %s", len(errs), len(r.recorders), strings.Join(errs, "; "))
+	}
+	return nil
+}
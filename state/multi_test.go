@@ -0,0 +1,64 @@
+package state
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type countingRecorder struct {
+	testRecorder
+	steps int
+}
+
+func (r *countingRecorder) RecordStep(step *Step) error {
+	r.steps++
+	return r.err
+}
+
+func TestMultiRecorder(t *testing.T) {
+	r1 := &countingRecorder{testRecorder: testRecorder{err: errors.New("unavailable")}}
+	r2 := &countingRecorder{}
+
+	recorder := NewMultiRecorder(r1, r2)
+
+	err := recorder.RecordStep(&Step{Id: 1})
+	assert.NotNil(t, err)
+	assert.Equal(t, 1, r1.steps)
+	assert.Equal(t, 1, r2.steps)
+
+	assert.Nil(t, NewMultiRecorder(r2).RecordStep(&Step{Id: 2}))
+}
+
+type cancelRecorder struct {
+	testRecorder
+	markers map[string]*CancelMarker
+}
+
+func (r *cancelRecorder) RecordCancel(marker *CancelMarker) error {
+	r.markers[marker.FlowID] = marker
+	return nil
+}
+
+func (r *cancelRecorder) GetCancel(flowID string) (*CancelMarker, error) {
+	return r.markers[flowID], nil
+}
+
+func TestMultiRecorderCancel(t *testing.T) {
+	r1 := &countingRecorder{}
+	r2 := &cancelRecorder{markers: make(map[string]*CancelMarker)}
+
+	assert.NotNil(t, RecordCancel(NewMultiRecorder(r1), &CancelMarker{FlowID: "1"}))
+
+	recorder := NewMultiRecorder(r1, r2)
+	assert.Nil(t, RecordCancel(recorder, &CancelMarker{FlowID: "1"}))
+
+	marker, err := GetCancel(recorder, "1")
+	assert.Nil(t, err)
+	assert.Equal(t, "1", marker.FlowID)
+
+	marker, err = GetCancel(recorder, "2")
+	assert.Nil(t, err)
+	assert.Nil(t, marker)
+}
@@ -12,3 +12,16 @@ type Recorder interface {
 
 	RecordDone(state *FlowState) error
 }
+
+// Pinger is optionally implemented by a Recorder to check that the service it records to is reachable
+type Pinger interface {
+	Ping() error
+}
+
+// Ping pings the specified Recorder, a Recorder that doesn't implement Pinger is assumed to be reachable
+func Ping(recorder Recorder) error {
+	if p, ok := recorder.(Pinger); ok {
+		return p.Ping()
+	}
+	return nil
+}
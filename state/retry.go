@@ -0,0 +1,82 @@
+package state
+
+import (
+	"time"
+)
+
+// MaxRetryBackoff is the maximum delay between the retries of a RetryRecorder, the retries block
+// the instance that is recording its state
+const MaxRetryBackoff = 5 * time.Second
+
+// NewRetryRecorder wraps the specified Recorder, retrying each call that fails up to attempts
+// times.  The backoff is the delay before the first retry, it is doubled for each subsequent retry
+// up to MaxRetryBackoff.
+func NewRetryRecorder(recorder Recorder, attempts int, backoff time.Duration) Recorder {
+	return &retryRecorder{recorder: recorder, attempts: attempts, backoff: backoff}
+}
+
+type retryRecorder struct {
+	recorder Recorder
+	attempts int
+	backoff  time.Duration
+}
+
+func (r *retryRecorder) RecordStart(state *FlowState) error {
+	return r.retry(func() error { return r.recorder.RecordStart(state) })
+}
+
+func (r *retryRecorder) RecordSnapshot(snapshot *Snapshot) error {
+	return r.retry(func() error { return r.recorder.RecordSnapshot(snapshot) })
+}
+
+func (r *retryRecorder) RecordStep(step *Step) error {
+	return r.retry(func() error { return r.recorder.RecordStep(step) })
+}
+
+func (r *retryRecorder) RecordDone(state *FlowState) error {
+	return r.retry(func() error { return r.recorder.RecordDone(state) })
+}
+
+func (r *retryRecorder) Ping() error {
+	return Ping(r.recorder)
+}
+
+func (r *retryRecorder) GetSteps(flowID string) ([]*Step, error) {
+	return GetSteps(r.recorder, flowID)
+}
+
+func (r *retryRecorder) GetSnapshot(flowID string) (*Snapshot, error) {
+	return GetSnapshot(r.recorder, flowID)
+}
+
+func (r *retryRecorder) GetCheckpoint(flowID, name string) (*Snapshot, error) {
+	return GetCheckpoint(r.recorder, flowID, name)
+}
+
+func (r *retryRecorder) RecordCancel(marker *CancelMarker) error {
+	return RecordCancel(r.recorder, marker)
+}
+
+func (r *retryRecorder) GetCancel(flowID string) (*CancelMarker, error) {
+	return GetCancel(r.recorder, flowID)
+}
+
+func (r *retryRecorder) retry(f func() error) error {
+	err := f()
+	backoff := r.backoff
+	for i := 0; err != nil && i < r.attempts; i++ {
+		time.Sleep(backoff)
+		backoff = nextBackoff(backoff)
+		err = f()
+	}
+	return err
+}
+
+// nextBackoff doubles the backoff, up to MaxRetryBackoff
+func nextBackoff(backoff time.Duration) time.Duration {
+	backoff *= 2
+	if backoff > MaxRetryBackoff {
+		return MaxRetryBackoff
+	}
+	return backoff
+}
@@ -0,0 +1,59 @@
+package state
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type flakyRecorder struct {
+	failures int
+	calls    int
+}
+
+func (r *flakyRecorder) RecordStart(state *FlowState) error { return r.record() }
+
+func (r *flakyRecorder) RecordSnapshot(snapshot *Snapshot) error { return r.record() }
+
+func (r *flakyRecorder) RecordStep(step *Step) error { return r.record() }
+
+func (r *flakyRecorder) RecordDone(state *FlowState) error { return r.record() }
+
+func (r *flakyRecorder) record() error {
+	r.calls++
+	if r.calls <= r.failures {
+		return errors.New("unavailable")
+	}
+	return nil
+}
+
+func TestRetryRecorder(t *testing.T) {
+	flaky := &flakyRecorder{failures: 2}
+	recorder := NewRetryRecorder(flaky, 2, 0)
+	assert.Nil(t, recorder.RecordStart(&FlowState{}))
+	assert.Equal(t, 3, flaky.calls)
+
+	flaky = &flakyRecorder{failures: 3}
+	recorder = NewRetryRecorder(flaky, 2, 0)
+	assert.NotNil(t, recorder.RecordDone(&FlowState{}))
+	assert.Equal(t, 3, flaky.calls)
+}
+
+func TestRetryBackoffCapped(t *testing.T) {
+	assert.Equal(t, 2*time.Second, nextBackoff(time.Second))
+	assert.Equal(t, MaxRetryBackoff, nextBackoff(MaxRetryBackoff-time.Millisecond))
+	assert.Equal(t, MaxRetryBackoff, nextBackoff(MaxRetryBackoff))
+}
+
+func TestRetryEachRecorder(t *testing.T) {
+	flaky := &flakyRecorder{failures: 1}
+	counting := &countingRecorder{}
+
+	recorder := NewMultiRecorder(NewRetryRecorder(flaky, 2, 0), NewRetryRecorder(counting, 2, 0))
+	assert.Nil(t, recorder.RecordStep(&Step{Id: 1}))
+	assert.Equal(t, 2, flaky.calls)
+	// the step isn't recorded again to the recorder that succeeded
+	assert.Equal(t, 1, counting.steps)
+}
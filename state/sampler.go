@@ -0,0 +1,58 @@
+package state
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/project-flogo/core/data/coerce"
+)
+
+// Sampler decides which steps of a flow instance are recorded, the first and last steps of an
+// instance are always recorded.  Changes made by steps that aren't recorded are included in the
+// next recorded step.
+type Sampler interface {
+	// Sample returns true if the step should be recorded, statusChanged indicates that the
+	// status of the instance changed during the step
+	Sample(stepCount int, statusChanged bool) bool
+}
+
+const (
+	// SamplingEveryNth records every Nth step of an instance
+	SamplingEveryNth = "everyNth"
+	// SamplingStatusChange records the steps that change the status of an instance
+	SamplingStatusChange = "statusChange"
+)
+
+// NewSampler creates the Sampler for the specified sampling strategy, interval is only used
+// by SamplingEveryNth.  An empty strategy returns nil, which records every step.
+func NewSampler(strategy string, interval interface{}) (Sampler, error) {
+	switch {
+	case strategy == "":
+		return nil, nil
+	case strings.EqualFold(strategy, SamplingEveryNth):
+		n, err := coerce.ToInt(interval)
+		if err != nil || n < 1 {
+			return nil, fmt.Errorf("invalid sampling interval [%v], must be a positive integer", interval)
+		}
+		return EveryNthSampler(n), nil
+	case strings.EqualFold(strategy, SamplingStatusChange):
+		return StatusChangeSampler{}, nil
+	default:
+		return nil, fmt.Errorf("unsupported sampling strategy [%s]", strategy)
+	}
+}
+
+// EveryNthSampler records every Nth step
+type EveryNthSampler int
+
+func (n EveryNthSampler) Sample(stepCount int, statusChanged bool) bool {
+	return stepCount%int(n) == 0
+}
+
+// StatusChangeSampler records the steps that change the status of the instance
+type StatusChangeSampler struct {
+}
+
+func (StatusChangeSampler) Sample(stepCount int, statusChanged bool) bool {
+	return statusChanged
+}
@@ -0,0 +1,28 @@
+package state
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewSampler(t *testing.T) {
+	s, err := NewSampler("", nil)
+	assert.Nil(t, err)
+	assert.Nil(t, s)
+
+	s, err = NewSampler(SamplingEveryNth, "3")
+	assert.Nil(t, err)
+	assert.False(t, s.Sample(2, true))
+	assert.True(t, s.Sample(3, false))
+
+	s, err = NewSampler(SamplingStatusChange, nil)
+	assert.Nil(t, err)
+	assert.False(t, s.Sample(2, false))
+	assert.True(t, s.Sample(3, true))
+
+	_, err = NewSampler(SamplingEveryNth, 0)
+	assert.NotNil(t, err)
+	_, err = NewSampler("random", nil)
+	assert.NotNil(t, err)
+}
@@ -5,6 +5,12 @@ type Snapshot struct {
 	Id        string      `json:"id"`
 	WorkQueue []*WorkItem `json:"workQueue,omitempty"`
 	Subflows  []*Subflow  `json:"subflows,omitempty"`
+	// Checkpoint is the name of the checkpoint the snapshot was recorded for, if any
+	Checkpoint string `json:"checkpoint,omitempty"`
+	// PausedTaskId and PausedError are the ID and error of the failed task the instance is paused
+	// on, if any
+	PausedTaskId string `json:"pausedTaskId,omitempty"`
+	PausedError  string `json:"pausedError,omitempty"`
 }
 
 type Subflow struct {
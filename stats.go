@@ -0,0 +1,57 @@
+package flow
+
+import (
+	"sync"
+
+	"github.com/project-flogo/flow/state"
+)
+
+// EngineStatsJSON is a snapshot of the instances executed by the engine, it is intended for ad-hoc
+// inspection, use a metrics.Collector for monitoring
+type EngineStatsJSON struct {
+	// ActiveInstances is the number of flow instances currently being executed
+	ActiveInstances int `json:"activeInstances"`
+	// ActiveByFlow is the number of instances currently being executed, by flow URI
+	ActiveByFlow map[string]int `json:"activeByFlow"`
+	// Totals is the number of instances that finished since the engine started, by final status
+	// (see FlowStatusKey)
+	Totals map[string]int64 `json:"totals"`
+	// RecordingMode is the configured state recording mode
+	RecordingMode state.RecordingMode `json:"recordingMode"`
+}
+
+var (
+	totalsMu sync.Mutex // protects the instance totals
+	totals   = make(map[string]int64)
+)
+
+// countInstance counts an instance that finished with the specified status
+func countInstance(status string) {
+	totalsMu.Lock()
+	totals[status]++
+	totalsMu.Unlock()
+}
+
+// EngineStats returns a snapshot of the instances executed by the engine
+func EngineStats() EngineStatsJSON {
+	stats := EngineStatsJSON{
+		ActiveByFlow:  make(map[string]int),
+		Totals:        make(map[string]int64),
+		RecordingMode: stateRecordingMode,
+	}
+
+	riMu.RLock()
+	stats.ActiveInstances = len(runningInstances)
+	for _, ri := range runningInstances {
+		stats.ActiveByFlow[ri.flowURI]++
+	}
+	riMu.RUnlock()
+
+	totalsMu.Lock()
+	for status, count := range totals {
+		stats.Totals[status] = count
+	}
+	totalsMu.Unlock()
+
+	return stats
+}
@@ -0,0 +1,12 @@
+package flow
+
+// StepGate is consulted before each step of an instance is executed, returning false
+// pauses the instance until it is resumed using ResumeInstance
+type StepGate func(instanceID string, stepID int, taskID string) bool
+
+var stepGate StepGate
+
+// SetStepGate sets the StepGate used by all flow instances, nil removes it
+func SetStepGate(gate StepGate) {
+	stepGate = gate
+}
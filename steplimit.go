@@ -0,0 +1,38 @@
+package flow
+
+import (
+	"github.com/project-flogo/flow/definition"
+	flowsupport "github.com/project-flogo/flow/support"
+)
+
+// stepLimitIterations is the number of times the derived step limit of a flow allows all its tasks
+// and links to be executed, ex. by a loop spanning the whole flow
+const stepLimitIterations = 10000
+
+// flowStepLimit derives the maximum number of steps of the flow's instances from the number of its
+// tasks and links, it never exceeds the engine's maximum step count.  The steps of subflows are
+// counted against the limit of the instance, so flows with large subflows or iterations should set
+// an explicit MaxStepCount.
+func flowStepLimit(def *definition.Definition) int {
+	size := len(def.Tasks()) + len(def.Links())
+	if eh := def.GetErrorHandler(); eh != nil {
+		size += len(eh.Tasks())
+	}
+	if size == 0 {
+		size = 1
+	}
+
+	if size > maxStepCount/stepLimitIterations {
+		return maxStepCount
+	}
+	return size * stepLimitIterations
+}
+
+// stepLimit returns the maximum number of steps of the instances of the flow, the limit derived for
+// the action's flow doesn't apply when it is run with a different flow
+func (fa *FlowAction) stepLimit(flowURI string) int {
+	if fa.maxStepCount > 0 && flowURI == flowsupport.ResolveFlowURI(fa.flowURI) {
+		return fa.maxStepCount
+	}
+	return maxStepCount
+}
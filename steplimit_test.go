@@ -0,0 +1,23 @@
+package flow
+
+import (
+	"testing"
+
+	"github.com/project-flogo/core/activity"
+	"github.com/project-flogo/flow/definition"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFlowStepLimit(t *testing.T) {
+	assert.Equal(t, stepLimitIterations, flowStepLimit(newTestDefinition(t, "limit")))
+
+	def := newTestDefinition(t, "limit",
+		&definition.TaskRep{ID: "a", ActivityCfgRep: &activity.Config{Ref: "selftest-ok"}},
+		&definition.TaskRep{ID: "b", ActivityCfgRep: &activity.Config{Ref: "selftest-ok"}})
+	assert.Equal(t, 3*stepLimitIterations, flowStepLimit(def))
+
+	fa := &FlowAction{flowURI: "res://flow:limit", maxStepCount: 50}
+	assert.Equal(t, 50, fa.stepLimit("res://flow:limit"))
+	assert.Equal(t, maxStepCount, fa.stepLimit("res://flow:other"))
+	assert.Equal(t, maxStepCount, (&FlowAction{}).stepLimit("res://flow:limit"))
+}
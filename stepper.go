@@ -0,0 +1,84 @@
+package flow
+
+import (
+	"fmt"
+
+	"github.com/project-flogo/flow/instance"
+	"github.com/project-flogo/flow/model"
+	"github.com/project-flogo/flow/state"
+	flowsupport "github.com/project-flogo/flow/support"
+)
+
+// Stepper executes an instance of a flow one step at a time, ex. for interactive debuggers or
+// custom schedulers.  The instance isn't recorded, traced or included in the running instances,
+// and a Stepper must not be used concurrently.
+type Stepper struct {
+	inst    *instance.IndependentInstance
+	hasWork bool
+}
+
+// StepResult describes the state of an instance after a step
+type StepResult struct {
+	StepID int              `json:"stepId"`
+	Status model.FlowStatus `json:"status"`
+	// TaskID is the ID of the task executed by the step
+	TaskID string `json:"taskId,omitempty"`
+	// Done indicates that the instance has no more steps to execute
+	Done bool `json:"done"`
+}
+
+// StartStepper starts an instance of the flow with the inputs, without executing any of its steps
+func StartStepper(flowURI string, inputs map[string]interface{}) (*Stepper, error) {
+	if idGenerator == nil {
+		return nil, fmt.Errorf("cannot start flow, flow action not initialized")
+	}
+
+	flowURI = flowsupport.ResolveFlowURI(flowURI)
+	flowDef, _, err := flowsupport.GetDefinition(flowURI)
+	if err != nil {
+		return nil, err
+	}
+	if flowDef == nil {
+		return nil, fmt.Errorf("flow not found for URI: %s", flowURI)
+	}
+
+	inst, err := instance.NewIndependentInstance(idGenerator.NextAsString(), flowURI, flowDef, instance.NewStateInstanceRecorder(nil, state.RecordingModeOff, false), logger)
+	if err != nil {
+		return nil, err
+	}
+	inst.Start(inputs)
+
+	return &Stepper{inst: inst, hasWork: true}, nil
+}
+
+// Step executes the next step of the instance
+func (s *Stepper) Step() (*StepResult, error) {
+	if s.Done() {
+		return nil, fmt.Errorf("instance [%s] has no more steps to execute", s.inst.ID())
+	}
+
+	s.hasWork = s.inst.DoStep()
+
+	result := &StepResult{StepID: s.inst.StepID(), Status: s.inst.Status(), Done: s.Done()}
+	// the trace is capped, the step's record is the last one if a task was executed
+	if trace := s.inst.ExecutionTrace(); len(trace) > 0 && trace[len(trace)-1].StepID == result.StepID {
+		result.TaskID = trace[len(trace)-1].TaskID
+	}
+	return result, nil
+}
+
+// Done returns true if the instance has no more steps to execute
+func (s *Stepper) Done() bool {
+	return !s.hasWork || s.inst.Status() >= model.FlowStatusCompleted
+}
+
+// State returns a copy of the current attributes of the instance, the values of sensitive fields
+// are redacted
+func (s *Stepper) State() map[string]interface{} {
+	return redact(s.inst.Attributes())
+}
+
+// Instance returns the instance being executed
+func (s *Stepper) Instance() *instance.IndependentInstance {
+	return s.inst
+}
@@ -0,0 +1,23 @@
+package flow
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStepper(t *testing.T) {
+	inst := newTestInstance(t, "stepper")
+	inst.Start(map[string]interface{}{"in": "value"})
+
+	stepper := &Stepper{inst: inst, hasWork: true}
+	for i := 0; !stepper.Done() && i < 10; i++ {
+		_, err := stepper.Step()
+		assert.Nil(t, err)
+	}
+	assert.True(t, stepper.Done())
+	assert.Equal(t, "value", stepper.State()["in"])
+
+	_, err := stepper.Step()
+	assert.NotNil(t, err)
+}
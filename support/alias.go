@@ -0,0 +1,27 @@
+package support
+
+import "sync"
+
+var (
+	aliasMu     sync.RWMutex // protects the flow aliases
+	flowAliases map[string]string
+)
+
+// SetFlowAliases sets the alias table used to resolve flow URIs, ex. "flow://orders" to the
+// environment specific URI of the flow
+func SetFlowAliases(aliases map[string]string) {
+	aliasMu.Lock()
+	flowAliases = aliases
+	aliasMu.Unlock()
+}
+
+// ResolveFlowURI resolves the flow URI using the alias table, a URI without an alias is returned as is
+func ResolveFlowURI(flowURI string) string {
+	aliasMu.RLock()
+	defer aliasMu.RUnlock()
+
+	if resolved, ok := flowAliases[flowURI]; ok {
+		return resolved
+	}
+	return flowURI
+}
@@ -0,0 +1,15 @@
+package support
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResolveFlowURI(t *testing.T) {
+	SetFlowAliases(map[string]string{"flow://orders": "res://flow:orders_v2"})
+	defer SetFlowAliases(nil)
+
+	assert.Equal(t, "res://flow:orders_v2", ResolveFlowURI("flow://orders"))
+	assert.Equal(t, "res://flow:payments", ResolveFlowURI("res://flow:payments"))
+}
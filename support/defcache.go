@@ -0,0 +1,127 @@
+package support
+
+import (
+	"container/list"
+	"sync"
+	"time"
+
+	"github.com/project-flogo/flow/definition"
+	"github.com/project-flogo/flow/support/metrics"
+)
+
+// DefinitionCache caches the definitions of the flows loaded by the FlowManager, a definition
+// that is evicted is reloaded from the flow provider the next time it is used
+type DefinitionCache interface {
+	Get(uri string) (*definition.Definition, bool)
+	Put(uri string, def *definition.Definition)
+	Remove(uri string)
+}
+
+// NewMapDefinitionCache creates a DefinitionCache that never evicts definitions, this is the default
+func NewMapDefinitionCache() DefinitionCache {
+	return &mapDefinitionCache{defs: make(map[string]*definition.Definition)}
+}
+
+type mapDefinitionCache struct {
+	mu   sync.Mutex
+	defs map[string]*definition.Definition
+}
+
+func (c *mapDefinitionCache) Get(uri string) (*definition.Definition, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	def, exists := c.defs[uri]
+	return def, exists
+}
+
+func (c *mapDefinitionCache) Put(uri string, def *definition.Definition) {
+	c.mu.Lock()
+	c.defs[uri] = def
+	c.mu.Unlock()
+}
+
+func (c *mapDefinitionCache) Remove(uri string) {
+	c.mu.Lock()
+	delete(c.defs, uri)
+	c.mu.Unlock()
+}
+
+// NewLRUDefinitionCache creates a DefinitionCache that holds up to size definitions, evicting the
+// least recently used definition when full.  Definitions are also evicted ttl after they were
+// loaded, a ttl of 0 keeps them until they're the least recently used.  The cache emits the
+// DefinitionCacheHits, DefinitionCacheMisses and DefinitionCacheEvictions metrics.
+func NewLRUDefinitionCache(size int, ttl time.Duration) DefinitionCache {
+	return &lruDefinitionCache{size: size, ttl: ttl, entries: make(map[string]*list.Element), order: list.New()}
+}
+
+type lruEntry struct {
+	uri    string
+	def    *definition.Definition
+	loaded time.Time
+}
+
+type lruDefinitionCache struct {
+	mu      sync.Mutex
+	size    int
+	ttl     time.Duration
+	entries map[string]*list.Element
+	order   *list.List
+}
+
+func (c *lruDefinitionCache) Get(uri string) (*definition.Definition, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e, exists := c.entries[uri]
+	if !exists {
+		metrics.Count(metrics.DefinitionCacheMisses, 1, nil)
+		return nil, false
+	}
+
+	entry := e.Value.(*lruEntry)
+	if c.ttl > 0 && time.Since(entry.loaded) > c.ttl {
+		c.remove(e)
+		metrics.Count(metrics.DefinitionCacheMisses, 1, nil)
+		return nil, false
+	}
+
+	c.order.MoveToFront(e)
+	metrics.Count(metrics.DefinitionCacheHits, 1, nil)
+	return entry.def, true
+}
+
+func (c *lruDefinitionCache) Put(uri string, def *definition.Definition) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if e, exists := c.entries[uri]; exists {
+		entry := e.Value.(*lruEntry)
+		entry.def = def
+		entry.loaded = time.Now()
+		c.order.MoveToFront(e)
+		return
+	}
+
+	c.entries[uri] = c.order.PushFront(&lruEntry{uri: uri, def: def, loaded: time.Now()})
+
+	for c.size > 0 && c.order.Len() > c.size {
+		c.remove(c.order.Back())
+	}
+}
+
+func (c *lruDefinitionCache) Remove(uri string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if e, exists := c.entries[uri]; exists {
+		c.order.Remove(e)
+		delete(c.entries, uri)
+	}
+}
+
+func (c *lruDefinitionCache) remove(e *list.Element) {
+	c.order.Remove(e)
+	delete(c.entries, e.Value.(*lruEntry).uri)
+	metrics.Count(metrics.DefinitionCacheEvictions, 1, nil)
+}
@@ -0,0 +1,41 @@
+package support
+
+import (
+	"testing"
+	"time"
+
+	"github.com/project-flogo/flow/definition"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLRUDefinitionCache(t *testing.T) {
+	cache := NewLRUDefinitionCache(2, 0)
+	a, b, c := &definition.Definition{}, &definition.Definition{}, &definition.Definition{}
+
+	cache.Put("a", a)
+	cache.Put("b", b)
+	_, exists := cache.Get("a")
+	assert.True(t, exists)
+
+	// b is the least recently used
+	cache.Put("c", c)
+	_, exists = cache.Get("b")
+	assert.False(t, exists)
+	def, exists := cache.Get("a")
+	assert.True(t, exists)
+	assert.True(t, def == a)
+	_, exists = cache.Get("c")
+	assert.True(t, exists)
+}
+
+func TestLRUDefinitionCacheTTL(t *testing.T) {
+	cache := NewLRUDefinitionCache(0, 10*time.Millisecond)
+	cache.Put("a", &definition.Definition{})
+
+	_, exists := cache.Get("a")
+	assert.True(t, exists)
+
+	time.Sleep(20 * time.Millisecond)
+	_, exists = cache.Get("a")
+	assert.False(t, exists)
+}
@@ -18,6 +18,8 @@ func InitDefaultDefLookup(fManager *FlowManager, rManager *resource.Manager) {
 
 func GetDefinition(flowURI string) (*definition.Definition, bool, error) {
 
+	flowURI = ResolveFlowURI(flowURI)
+
 	var def *definition.Definition
 
 	if strings.HasPrefix(flowURI, resource.UriScheme) {
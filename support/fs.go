@@ -0,0 +1,55 @@
+package support
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"strings"
+	"sync"
+)
+
+const uriSchemeFlow = "flow://"
+
+type flowFS struct {
+	fsys   fs.FS
+	prefix string
+}
+
+var (
+	flowFSMu sync.RWMutex // protects the registered file systems
+	flowFSs  []*flowFS
+)
+
+// RegisterFlowFS registers a file system that "flow://" URIs are resolved against, ex. an embed.FS
+// compiled into the binary.  The path of a URI is relative to the prefix, so with the prefix "flows"
+// "flow://orders" resolves to "flows/orders.json".  File systems are searched in the order they
+// were registered.
+func RegisterFlowFS(fsys fs.FS, prefix string) {
+	flowFSMu.Lock()
+	flowFSs = append(flowFSs, &flowFS{fsys: fsys, prefix: strings.Trim(prefix, "/")})
+	flowFSMu.Unlock()
+}
+
+// readFlowFS reads the flow with the specified "flow://" URI from the registered file systems
+func readFlowFS(flowURI string) ([]byte, error) {
+	name := strings.Trim(strings.TrimPrefix(flowURI, uriSchemeFlow), "/")
+	if !strings.HasSuffix(name, ".json") {
+		name += ".json"
+	}
+
+	flowFSMu.RLock()
+	defer flowFSMu.RUnlock()
+
+	for _, ffs := range flowFSs {
+		data, err := fs.ReadFile(ffs.fsys, path.Join(ffs.prefix, name))
+		if err == nil {
+			return data, nil
+		}
+		if !errors.Is(err, fs.ErrNotExist) {
+			return nil, fmt.Errorf("error reading flow with uri '%s', %s", flowURI, err.Error())
+		}
+	}
+
+	return nil, fmt.Errorf("flow with uri '%s' not found in any registered file system", flowURI)
+}
@@ -0,0 +1,49 @@
+package support
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadFlowFS(t *testing.T) {
+	RegisterFlowFS(fstest.MapFS{
+		"flows/orders.json": &fstest.MapFile{Data: []byte(`{"name":"orders"}`)},
+	}, "flows")
+	defer func() { flowFSs = nil }()
+
+	data, err := readFlowFS("flow://orders")
+	assert.Nil(t, err)
+	assert.Equal(t, `{"name":"orders"}`, string(data))
+
+	data, err = readFlowFS("flow://orders.json")
+	assert.Nil(t, err)
+	assert.NotNil(t, data)
+
+	_, err = readFlowFS("flow://payments")
+	assert.NotNil(t, err)
+}
+
+func TestReadFlowFSDir(t *testing.T) {
+	dir := t.TempDir()
+	assert.Nil(t, os.Mkdir(filepath.Join(dir, "flows"), 0755))
+	assert.Nil(t, os.WriteFile(filepath.Join(dir, "flows", "orders.json"), []byte(`{"name":"orders"}`), 0644))
+
+	// a flow missing from the directory is looked up in the next file system
+	RegisterFlowFS(os.DirFS(dir), "flows")
+	RegisterFlowFS(fstest.MapFS{
+		"payments.json": &fstest.MapFile{Data: []byte(`{"name":"payments"}`)},
+	}, "")
+	defer func() { flowFSs = nil }()
+
+	data, err := readFlowFS("flow://orders")
+	assert.Nil(t, err)
+	assert.Equal(t, `{"name":"orders"}`, string(data))
+
+	data, err = readFlowFS("flow://payments")
+	assert.Nil(t, err)
+	assert.Equal(t, `{"name":"payments"}`, string(data))
+}
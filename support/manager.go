@@ -9,7 +9,6 @@ import (
 	"io/ioutil"
 	"net/http"
 	"strings"
-	"sync"
 
 	"github.com/project-flogo/core/support"
 	"github.com/project-flogo/core/support/log"
@@ -22,14 +21,12 @@ const (
 )
 
 type FlowManager struct {
-	//todo switch to cache
-	rfMu         sync.Mutex // protects the flow maps
-	remoteFlows  map[string]*definition.Definition
+	flowCache    DefinitionCache
 	flowProvider definition.Provider
 }
 
 func NewFlowManager(flowProvider definition.Provider) *FlowManager {
-	manager := &FlowManager{}
+	manager := &FlowManager{flowCache: NewMapDefinitionCache()}
 
 	if flowProvider != nil {
 		manager.flowProvider = flowProvider
@@ -41,16 +38,24 @@ func NewFlowManager(flowProvider definition.Provider) *FlowManager {
 	return manager
 }
 
-func (fm *FlowManager) GetFlow(uri string) (*definition.Definition, error) {
+// SetDefinitionCache sets the cache of the definitions loaded by the manager, nil restores the
+// default cache which never evicts definitions
+func (fm *FlowManager) SetDefinitionCache(cache DefinitionCache) {
+	if cache == nil {
+		cache = NewMapDefinitionCache()
+	}
+	fm.flowCache = cache
+}
 
-	fm.rfMu.Lock()
-	defer fm.rfMu.Unlock()
+// Evict removes the cached definition of the flow, it is loaded from the flow provider the next
+// time it is used
+func (fm *FlowManager) Evict(uri string) {
+	fm.flowCache.Remove(uri)
+}
 
-	if fm.remoteFlows == nil {
-		fm.remoteFlows = make(map[string]*definition.Definition)
-	}
+func (fm *FlowManager) GetFlow(uri string) (*definition.Definition, error) {
 
-	flow, exists := fm.remoteFlows[uri]
+	flow, exists := fm.flowCache.Get(uri)
 
 	if !exists {
 
@@ -64,7 +69,7 @@ func (fm *FlowManager) GetFlow(uri string) (*definition.Definition, error) {
 			return nil, err
 		}
 
-		fm.remoteFlows[uri] = flow
+		fm.flowCache.Put(uri, flow)
 	}
 
 	return flow, nil
@@ -142,6 +147,13 @@ func (fp *BasicRemoteFlowProvider) GetFlow(flowURI string) (*definition.Definiti
 		} else {
 			flowDefBytes = body
 		}
+	} else if strings.HasPrefix(flowURI, uriSchemeFlow) {
+		readBytes, err := readFlowFS(flowURI)
+		if err != nil {
+			logger.Errorf(err.Error())
+			return nil, err
+		}
+		flowDefBytes = readBytes
 	} else {
 		return nil, fmt.Errorf("unsupport uri %s", flowURI)
 	}
@@ -0,0 +1,59 @@
+package metrics
+
+import (
+	"time"
+)
+
+const (
+	// RecorderLatency is the timing metric for calls to the state recorder
+	RecorderLatency = "flow.recorder.latency"
+	// RecorderErrors is the counter metric for failed calls to the state recorder
+	RecorderErrors = "flow.recorder.errors"
+	// InstanceDuration is the timing metric for the execution of flow instances
+	InstanceDuration = "flow.instance.duration"
+	// InstanceCompleted is the counter metric for completed flow instances
+	InstanceCompleted = "flow.instance.completed"
+	// InstanceFailed is the counter metric for failed flow instances
+	InstanceFailed = "flow.instance.failed"
+	// DefinitionCacheHits is the counter metric for definitions found in the definition cache
+	DefinitionCacheHits = "flow.definition.cache.hits"
+	// DefinitionCacheMisses is the counter metric for definitions loaded because they weren't cached
+	DefinitionCacheMisses = "flow.definition.cache.misses"
+	// DefinitionCacheEvictions is the counter metric for definitions evicted from the definition cache
+	DefinitionCacheEvictions = "flow.definition.cache.evictions"
+)
+
+// Collector receives the metrics emitted by the flow engine
+type Collector interface {
+	// Timing records the duration of the named operation
+	Timing(name string, duration time.Duration, labels map[string]string)
+
+	// Count increments the named counter by delta
+	Count(name string, delta int64, labels map[string]string)
+}
+
+var collector Collector
+
+// SetCollector sets the Collector that receives the flow engine metrics, nil disables collection
+func SetCollector(c Collector) {
+	collector = c
+}
+
+// Enabled checks if a Collector has been set
+func Enabled() bool {
+	return collector != nil
+}
+
+// Timing records the duration of the named operation if a Collector has been set
+func Timing(name string, duration time.Duration, labels map[string]string) {
+	if collector != nil {
+		collector.Timing(name, duration, labels)
+	}
+}
+
+// Count increments the named counter if a Collector has been set
+func Count(name string, delta int64, labels map[string]string) {
+	if collector != nil {
+		collector.Count(name, delta, labels)
+	}
+}
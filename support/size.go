@@ -0,0 +1,75 @@
+package support
+
+import (
+	"encoding/json"
+	"io"
+	"reflect"
+)
+
+// EstimateSize estimates the number of bytes the specified value occupies when serialized
+// to JSON, without actually serializing common types
+func EstimateSize(val interface{}) int {
+	switch t := val.(type) {
+	case nil:
+		return 4
+	case io.Reader:
+		// streams are recorded as a placeholder
+		return len(StreamPlaceholder) + 2
+	case string:
+		return len(t) + 2
+	case []byte:
+		return len(t)
+	case json.RawMessage:
+		return len(t)
+	case bool:
+		return 5
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64, json.Number:
+		return 8
+	case map[string]interface{}:
+		size := 2
+		for k, v := range t {
+			size += len(k) + 4 + EstimateSize(v)
+		}
+		return size
+	case map[string]string:
+		size := 2
+		for k, v := range t {
+			size += len(k) + len(v) + 6
+		}
+		return size
+	case []interface{}:
+		size := 2
+		for _, v := range t {
+			size += EstimateSize(v) + 1
+		}
+		return size
+	}
+
+	rv := reflect.ValueOf(val)
+	switch rv.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if rv.IsNil() {
+			return 4
+		}
+		return EstimateSize(rv.Elem().Interface())
+	case reflect.Slice, reflect.Array:
+		size := 2
+		for i := 0; i < rv.Len(); i++ {
+			size += EstimateSize(rv.Index(i).Interface()) + 1
+		}
+		return size
+	case reflect.Map:
+		size := 2
+		iter := rv.MapRange()
+		for iter.Next() {
+			size += EstimateSize(iter.Key().Interface()) + EstimateSize(iter.Value().Interface()) + 2
+		}
+		return size
+	}
+
+	b, err := json.Marshal(val)
+	if err != nil {
+		return 0
+	}
+	return len(b)
+}
@@ -0,0 +1,28 @@
+package support
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEstimateSize(t *testing.T) {
+	assert.Equal(t, 7, EstimateSize("hello"))
+	assert.Equal(t, 8, EstimateSize(42))
+
+	small := map[string]interface{}{"a": "b"}
+	large := map[string]interface{}{"a": make([]interface{}, 1000)}
+	assert.True(t, EstimateSize(large) > EstimateSize(small))
+
+	b, _ := json.Marshal(map[string]interface{}{"name": "flogo", "tags": []interface{}{"a", "b"}})
+	est := EstimateSize(map[string]interface{}{"name": "flogo", "tags": []string{"a", "b"}})
+	assert.InDelta(t, len(b), est, 10)
+}
+
+func TestEstimateSizeStream(t *testing.T) {
+	assert.Equal(t, len(StreamPlaceholder)+2, EstimateSize(strings.NewReader("a large payload")))
+	assert.Equal(t, StreamPlaceholder, RecordableValue(strings.NewReader("a large payload")))
+	assert.Equal(t, "value", RecordableValue("value"))
+}
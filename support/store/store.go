@@ -0,0 +1,114 @@
+package store
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/project-flogo/core/data/coerce"
+)
+
+// SharedStore is a concurrency-safe store of values shared across flow instances, ex. a
+// counter limiting the total number of messages sent per day.  The default in-memory store
+// is lost when the engine stops, durability requires a custom SharedStore backed by an
+// external system.
+type SharedStore interface {
+	// Incr increments the integer value of the key by delta, returning the new value.  A key
+	// that doesn't exist is created with the specified ttl, a zero ttl never expires.
+	Incr(key string, delta int64, ttl time.Duration) (int64, error)
+
+	// Get returns the value of the key
+	Get(key string) (value interface{}, exists bool, err error)
+
+	// Set sets the value of the key, a zero ttl never expires
+	Set(key string, value interface{}, ttl time.Duration) error
+}
+
+var sharedStore SharedStore = NewMemoryStore()
+
+// SetSharedStore sets the SharedStore available to activities, nil restores the default
+// in-memory store
+func SetSharedStore(s SharedStore) {
+	if s == nil {
+		s = NewMemoryStore()
+	}
+	sharedStore = s
+}
+
+// GetSharedStore returns the SharedStore available to activities
+func GetSharedStore() SharedStore {
+	return sharedStore
+}
+
+type entry struct {
+	value   interface{}
+	expires time.Time
+}
+
+func (e *entry) expired(now time.Time) bool {
+	return !e.expires.IsZero() && now.After(e.expires)
+}
+
+// MemoryStore is an in-memory SharedStore
+type MemoryStore struct {
+	mu      sync.Mutex
+	entries map[string]*entry
+}
+
+// NewMemoryStore creates a new in-memory SharedStore
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{entries: make(map[string]*entry)}
+}
+
+func (s *MemoryStore) Incr(key string, delta int64, ttl time.Duration) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	e, ok := s.entries[key]
+	if !ok || e.expired(now) {
+		e = &entry{value: int64(0), expires: expiry(now, ttl)}
+		s.entries[key] = e
+	}
+
+	val, err := coerce.ToInt64(e.value)
+	if err != nil {
+		return 0, fmt.Errorf("value of key '%s' is not an integer", key)
+	}
+
+	val += delta
+	e.value = val
+
+	return val, nil
+}
+
+func (s *MemoryStore) Get(key string) (interface{}, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	e, ok := s.entries[key]
+	if !ok {
+		return nil, false, nil
+	}
+	if e.expired(time.Now()) {
+		delete(s.entries, key)
+		return nil, false, nil
+	}
+
+	return e.value, true, nil
+}
+
+func (s *MemoryStore) Set(key string, value interface{}, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.entries[key] = &entry{value: value, expires: expiry(time.Now(), ttl)}
+	return nil
+}
+
+func expiry(now time.Time, ttl time.Duration) time.Time {
+	if ttl <= 0 {
+		return time.Time{}
+	}
+	return now.Add(ttl)
+}
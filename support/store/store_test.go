@@ -0,0 +1,48 @@
+package store
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryStoreIncr(t *testing.T) {
+	s := NewMemoryStore()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := s.Incr("sends", 1, 0)
+			assert.Nil(t, err)
+		}()
+	}
+	wg.Wait()
+
+	val, exists, err := s.Get("sends")
+	assert.Nil(t, err)
+	assert.True(t, exists)
+	assert.Equal(t, int64(50), val)
+
+	_ = s.Set("name", "flogo", 0)
+	_, err = s.Incr("name", 1, 0)
+	assert.NotNil(t, err)
+}
+
+func TestMemoryStoreTTL(t *testing.T) {
+	s := NewMemoryStore()
+
+	_ = s.Set("key", "val", time.Millisecond)
+	time.Sleep(5 * time.Millisecond)
+
+	_, exists, err := s.Get("key")
+	assert.Nil(t, err)
+	assert.False(t, exists)
+
+	val, err := s.Incr("key", 2, 0)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(2), val)
+}
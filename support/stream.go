@@ -0,0 +1,22 @@
+package support
+
+import "io"
+
+// StreamPlaceholder is recorded in place of the value of a streamed attribute
+const StreamPlaceholder = "<stream>"
+
+// IsStream returns true if the value is streamed, ie. an io.Reader.  Streamed values are passed
+// to activities as is, the engine never copies or serializes them.
+func IsStream(val interface{}) bool {
+	_, ok := val.(io.Reader)
+	return ok
+}
+
+// RecordableValue returns the value to record in the state of an instance, StreamPlaceholder for
+// streamed values
+func RecordableValue(val interface{}) interface{} {
+	if IsStream(val) {
+		return StreamPlaceholder
+	}
+	return val
+}
@@ -0,0 +1,207 @@
+package flow
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/project-flogo/flow/instance"
+)
+
+// SuspendTokenKey is the key of the result that contains the correlation token when an
+// instance has been suspended
+const SuspendTokenKey = "_suspend_token"
+
+// ErrNotSuspended is returned by a SuspensionStore when no instance is suspended with a token
+var ErrNotSuspended = errors.New("no instance suspended with token")
+
+// SuspensionStore holds the suspended instances until they are resumed, keyed by the token they
+// were suspended with.  The default store keeps them in memory, so they don't survive a restart of
+// the engine.  A durable implementation (ex. backed by a database) must restore a loaded instance
+// with IndependentInstance.Restart(logger, inst.ID(), 0) so it is ready to continue.  Note that
+// the JSON encoding of an instance holds its state and the failed task it's paused on, if any, but
+// not its execution options (ex. PauseOnError), log ID and execution trace.  Such an instance is
+// resumed with the execution options passed in RunOptions.ExecOptions.  Implementations must be
+// safe for concurrent use.
+type SuspensionStore interface {
+	// Save stores the suspended instance, replacing any instance stored with the token
+	Save(token string, inst *instance.IndependentInstance) error
+	// Load returns the instance suspended with the token, ErrNotSuspended is returned if there is none
+	Load(token string) (*instance.IndependentInstance, error)
+	// Delete removes the instance suspended with the token, once it has been resumed or aborted.
+	// ErrNotSuspended must be returned if there is none, ex. it was already resumed, so that an
+	// instance is only resumed once.
+	Delete(token string) error
+}
+
+// SuspensionTaker is optionally implemented by a SuspensionStore that can load and delete the
+// instance suspended with a token as a single atomic operation
+type SuspensionTaker interface {
+	// Take removes and returns the instance suspended with the token, ErrNotSuspended is returned
+	// if there is none
+	Take(token string) (*instance.IndependentInstance, error)
+}
+
+// SuspensionLister is optionally implemented by a SuspensionStore that can list its tokens, it is
+// required to list the suspended instances (see PausedOnErrorInstances and PauseAll)
+type SuspensionLister interface {
+	// Tokens returns the tokens of all the suspended instances
+	Tokens() ([]string, error)
+}
+
+// NewMemorySuspensionStore creates an in-process SuspensionStore, this is the default
+func NewMemorySuspensionStore() SuspensionStore {
+	return &memorySuspensionStore{instances: make(map[string]*instance.IndependentInstance)}
+}
+
+type memorySuspensionStore struct {
+	mu        sync.Mutex
+	instances map[string]*instance.IndependentInstance
+}
+
+func (s *memorySuspensionStore) Save(token string, inst *instance.IndependentInstance) error {
+	s.mu.Lock()
+	s.instances[token] = inst
+	s.mu.Unlock()
+	return nil
+}
+
+func (s *memorySuspensionStore) Load(token string) (*instance.IndependentInstance, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	inst, ok := s.instances[token]
+	if !ok {
+		return nil, ErrNotSuspended
+	}
+	return inst, nil
+}
+
+func (s *memorySuspensionStore) Delete(token string) error {
+	_, err := s.Take(token)
+	return err
+}
+
+func (s *memorySuspensionStore) Take(token string) (*instance.IndependentInstance, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	inst, ok := s.instances[token]
+	if !ok {
+		return nil, ErrNotSuspended
+	}
+	delete(s.instances, token)
+	return inst, nil
+}
+
+func (s *memorySuspensionStore) Tokens() ([]string, error) {
+	s.mu.Lock()
+	tokens := make([]string, 0, len(s.instances))
+	for token := range s.instances {
+		tokens = append(tokens, token)
+	}
+	s.mu.Unlock()
+	return tokens, nil
+}
+
+var suspensionStore = NewMemorySuspensionStore()
+
+// SetSuspensionStore sets the SuspensionStore used to hold suspended instances, nil restores the
+// default in-process store.  It should be set before any instance is suspended.
+func SetSuspensionStore(store SuspensionStore) {
+	if store == nil {
+		store = NewMemorySuspensionStore()
+	}
+	suspensionStore = store
+}
+
+func suspendInstance(token string, inst *instance.IndependentInstance) error {
+	return suspensionStore.Save(token, inst)
+}
+
+// takeSuspendedInstance removes and returns the instance suspended with the specified token, only
+// one of concurrent takes of the same token gets the instance, the others get ErrNotSuspended
+func takeSuspendedInstance(token string) (*instance.IndependentInstance, error) {
+	if taker, ok := suspensionStore.(SuspensionTaker); ok {
+		return taker.Take(token)
+	}
+
+	inst, err := suspensionStore.Load(token)
+	if err != nil {
+		return nil, err
+	}
+	// the store deleting the instance decides which take gets it
+	if err := suspensionStore.Delete(token); err != nil {
+		return nil, err
+	}
+	return inst, nil
+}
+
+// suspendedInstances returns the suspended instances keyed by token, the store must implement
+// SuspensionLister
+func suspendedInstances() (map[string]*instance.IndependentInstance, error) {
+	lister, ok := suspensionStore.(SuspensionLister)
+	if !ok {
+		return nil, fmt.Errorf("suspension store does not support listing suspended instances")
+	}
+
+	tokens, err := lister.Tokens()
+	if err != nil {
+		return nil, err
+	}
+
+	instances := make(map[string]*instance.IndependentInstance, len(tokens))
+	for _, token := range tokens {
+		inst, err := suspensionStore.Load(token)
+		if err == ErrNotSuspended {
+			// resumed since it was listed
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		instances[token] = inst
+	}
+	return instances, nil
+}
+
+// PausedOnErrorInstances returns information about the instances suspended because a task failed
+// (see ExecOptions.PauseOnError), with the task's error.  They are resumed with OpResume using
+// their ID as the RunOptions.ResumeToken, or discarded using AbortSuspendedInstance.
+func PausedOnErrorInstances() []*InstanceInfo {
+	var infos []*InstanceInfo
+
+	instances, err := suspendedInstances()
+	if err != nil {
+		logger.Warnf("Unable to list suspended instances: %v", err)
+		return nil
+	}
+
+	for _, inst := range instances {
+		if err := inst.PausedError(); err != nil {
+			infos = append(infos, &InstanceInfo{ID: inst.ID(), FlowURI: inst.FlowURI(), FlowName: inst.Name(),
+				Status: inst.Status(), Paused: true, Labels: inst.Labels(), Error: err.Error()})
+		}
+	}
+
+	sort.Slice(infos, func(i, j int) bool {
+		return infos[i].ID < infos[j].ID
+	})
+	return infos
+}
+
+// AbortSuspendedInstance discards the instance suspended with the specified token, it can no
+// longer be resumed
+func AbortSuspendedInstance(token string) error {
+	inst, err := takeSuspendedInstance(token)
+	if err == ErrNotSuspended {
+		return fmt.Errorf("no instance suspended with token [%s]", token)
+	}
+	if err != nil {
+		return fmt.Errorf("unable to abort instance suspended with token [%s]: %s", token, err.Error())
+	}
+
+	logger.Infof("Flow Instance [%s] suspended with token [%s] aborted", inst.ID(), token)
+	return nil
+}
@@ -0,0 +1,215 @@
+package flow
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/project-flogo/core/activity"
+	"github.com/project-flogo/core/support"
+	"github.com/project-flogo/core/support/log"
+	"github.com/project-flogo/flow/definition"
+	"github.com/project-flogo/flow/instance"
+	"github.com/project-flogo/flow/model"
+	flowsupport "github.com/project-flogo/flow/support"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPauseOnError(t *testing.T) {
+	inst := newTestInstance(t, "pause", &definition.TaskRep{ID: "check", ActivityCfgRep: &activity.Config{Ref: "selftest-fail"}})
+	instance.ApplyExecOptions(inst, &instance.ExecOptions{PauseOnError: true})
+	inst.Start(nil)
+
+	for i := 0; i < 10 && inst.SuspendToken() == ""; i++ {
+		inst.DoStep()
+	}
+	assert.Equal(t, "pause", inst.SuspendToken())
+	assert.Equal(t, model.FlowStatusActive, inst.Status())
+	assert.NotNil(t, inst.PausedError())
+
+	assert.Nil(t, suspendInstance(inst.SuspendToken(), inst))
+	infos := PausedOnErrorInstances()
+	assert.Len(t, infos, 1)
+	assert.Equal(t, "pause", infos[0].ID)
+	assert.Contains(t, infos[0].Error, "dependency unavailable")
+
+	resumed, err := takeSuspendedInstance("pause")
+	assert.Nil(t, err)
+	resumed.ClearSuspend()
+	resumed.RetryPausedTask()
+	assert.Nil(t, resumed.PausedError())
+	assert.Empty(t, PausedOnErrorInstances())
+
+	// the task fails again
+	for i := 0; i < 10 && resumed.SuspendToken() == ""; i++ {
+		resumed.DoStep()
+	}
+	assert.NotNil(t, resumed.PausedError())
+
+	assert.NotNil(t, AbortSuspendedInstance("unknown"))
+}
+
+func TestPauseOnErrorRestored(t *testing.T) {
+	def := newTestDefinition(t, "pause", &definition.TaskRep{ID: "check", ActivityCfgRep: &activity.Config{Ref: "selftest-fail"}})
+
+	cache := flowsupport.NewMapDefinitionCache()
+	cache.Put("local://pause", def)
+	fm := flowsupport.NewFlowManager(nil)
+	fm.SetDefinitionCache(cache)
+	flowsupport.InitDefaultDefLookup(fm, nil)
+
+	inst, err := instance.NewIndependentInstance("pause-2", "local://pause", def, nil, log.RootLogger())
+	assert.Nil(t, err)
+	instance.ApplyExecOptions(inst, &instance.ExecOptions{PauseOnError: true})
+	inst.Start(nil)
+	for i := 0; i < 10 && inst.SuspendToken() == ""; i++ {
+		inst.DoStep()
+	}
+	assert.NotNil(t, inst.PausedError())
+	assert.Equal(t, "check", inst.Snapshot().PausedTaskId)
+
+	// the instance is restored by a durable store
+	data, err := json.Marshal(inst)
+	assert.Nil(t, err)
+	restored := &instance.IndependentInstance{}
+	assert.Nil(t, json.Unmarshal(data, restored))
+	assert.Nil(t, restored.Restart(log.RootLogger(), "pause-2", 0))
+	instance.ApplyExecOptions(restored, &instance.ExecOptions{PauseOnError: true})
+	assert.Contains(t, restored.PausedError().Error(), "dependency unavailable")
+
+	restored.ClearSuspend()
+	restored.RetryPausedTask()
+	assert.Nil(t, restored.PausedError())
+
+	// the task is evaluated, and fails, again
+	for i := 0; i < 10 && restored.SuspendToken() == ""; i++ {
+		restored.DoStep()
+	}
+	assert.NotNil(t, restored.PausedError())
+}
+
+func TestMemorySuspensionStore(t *testing.T) {
+	store := NewMemorySuspensionStore()
+
+	_, err := store.Load("unknown")
+	assert.Equal(t, ErrNotSuspended, err)
+
+	inst := &instance.IndependentInstance{}
+	assert.Nil(t, store.Save("token-1", inst))
+	loaded, err := store.Load("token-1")
+	assert.Nil(t, err)
+	assert.Equal(t, inst, loaded)
+
+	tokens, err := store.(SuspensionLister).Tokens()
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"token-1"}, tokens)
+
+	assert.Nil(t, store.Delete("token-1"))
+	_, err = store.Load("token-1")
+	assert.Equal(t, ErrNotSuspended, err)
+	assert.Equal(t, ErrNotSuspended, store.Delete("token-1"))
+}
+
+// loadDeleteStore is a SuspensionStore that doesn't implement SuspensionTaker
+type loadDeleteStore struct {
+	SuspensionStore
+}
+
+func TestTakeSuspendedInstanceOnce(t *testing.T) {
+	defer SetSuspensionStore(nil)
+
+	for _, store := range []SuspensionStore{NewMemorySuspensionStore(), &loadDeleteStore{NewMemorySuspensionStore()}} {
+		SetSuspensionStore(store)
+		assert.Nil(t, suspendInstance("token-1", &instance.IndependentInstance{}))
+
+		var wg sync.WaitGroup
+		var mu sync.Mutex
+		taken := 0
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if _, err := takeSuspendedInstance("token-1"); err == nil {
+					mu.Lock()
+					taken++
+					mu.Unlock()
+				} else {
+					assert.Equal(t, ErrNotSuspended, err)
+				}
+			}()
+		}
+		wg.Wait()
+		assert.Equal(t, 1, taken)
+	}
+}
+
+// resumingStore is a SuspensionStore that changes an instance as soon as it is saved, as a
+// concurrent resume would
+type resumingStore struct {
+	SuspensionStore
+	err error
+}
+
+func (s *resumingStore) Save(token string, inst *instance.IndependentInstance) error {
+	if s.err != nil {
+		return s.err
+	}
+	if err := s.SuspensionStore.Save(token, inst); err != nil {
+		return err
+	}
+	inst.SetLabels(map[string]string{"resumed": "true"})
+	inst.ClearSuspend()
+	return nil
+}
+
+func TestSuspendedInstanceSavedLast(t *testing.T) {
+	logger = log.ChildLogger(log.RootLogger(), "flow")
+	if idGenerator == nil {
+		idGenerator, _ = support.NewGenerator()
+	}
+	defer SetSuspensionStore(nil)
+	instanceRetention = time.Minute
+	defer func() { instanceRetention = 0 }()
+
+	def := newTestDefinition(t, "saved", &definition.TaskRep{ID: "check", ActivityCfgRep: &activity.Config{Ref: "selftest-fail"}})
+	fa := &FlowAction{flowURI: "res://flow:saved", resFlow: def, allowEmptyInputs: true}
+	run := func() *testResultHandler {
+		handler := &testResultHandler{done: make(chan struct{})}
+		ro := &instance.RunOptions{Op: instance.OpStart, Labels: map[string]string{"tenant": "acme"},
+			ExecOptions: &instance.ExecOptions{PauseOnError: true, Envelope: true}}
+		assert.Nil(t, fa.Run(context.Background(), map[string]interface{}{"_run_options": ro}, handler))
+		select {
+		case <-handler.done:
+		case <-time.After(5 * time.Second):
+			t.Fatal("instance not done")
+		}
+		return handler
+	}
+
+	// the instance is finished before it is saved
+	SetSuspensionStore(&resumingStore{SuspensionStore: NewMemorySuspensionStore()})
+	handler := run()
+	assert.Nil(t, handler.err)
+	data, _ := handler.results["data"].(map[string]interface{})
+	meta, _ := handler.results["meta"].(map[string]interface{})
+	assert.NotEmpty(t, data[SuspendTokenKey])
+	assert.Equal(t, FlowStatusSuspended, meta["status"])
+	info, exists := GetRunningInstance(meta["instanceId"].(string))
+	if assert.True(t, exists) {
+		assert.Equal(t, map[string]string{"tenant": "acme"}, info.Labels)
+	}
+
+	// an instance that can't be saved fails
+	SetSuspensionStore(&resumingStore{SuspensionStore: NewMemorySuspensionStore(), err: errors.New("store unavailable")})
+	handler = run()
+	if assert.NotNil(t, handler.err) {
+		assert.Contains(t, handler.err.Error(), "store unavailable")
+	}
+	meta, _ = handler.results["meta"].(map[string]interface{})
+	assert.Equal(t, FlowStatusFailed, meta["status"])
+	info, _ = GetRunningInstance(meta["instanceId"].(string))
+	assert.Equal(t, model.FlowStatusFailed, info.Status)
+}
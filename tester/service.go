@@ -9,6 +9,7 @@ import (
 	"github.com/project-flogo/core/data/coerce"
 	"github.com/project-flogo/core/support/log"
 	"github.com/project-flogo/core/support/service"
+	"github.com/project-flogo/flow"
 	"github.com/project-flogo/flow/instance"
 )
 
@@ -71,6 +72,9 @@ func (ft *RestFlowTester) init(settings map[string]interface{}) error {
 	router.OPTIONS("/status", handleOption)
 	router.GET("/status", ft.Status)
 
+	router.OPTIONS("/stats", handleOption)
+	router.GET("/stats", ft.Stats)
+
 	port := 8080
 	var err error
 	sPort, set := settings["port"]
@@ -250,3 +254,15 @@ func (ft *RestFlowTester) Status(w http.ResponseWriter, r *http.Request, _ httpr
 	_, _ = w.Write([]byte(`{"status":"ok"}`))
 	//w.WriteHeader(http.StatusOK)
 }
+
+// Stats returns a snapshot of the instances executed by the engine (GET "/stats").
+//
+// To test:
+// $ curl http://localhost:8080/stats
+func (ft *RestFlowTester) Stats(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+
+	w.Header().Add("Access-Control-Allow-Origin", "*")
+	w.Header().Set("Content-Type", "application/json")
+
+	_ = json.NewEncoder(w).Encode(flow.EngineStats())
+}
@@ -0,0 +1,122 @@
+package flow
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/project-flogo/flow/instance"
+	"github.com/project-flogo/flow/model"
+)
+
+// traceFileEvent is a line of the trace file, it describes a step of an instance
+type traceFileEvent struct {
+	InstanceID string           `json:"instanceId"`
+	FlowName   string           `json:"flowName"`
+	FlowStatus string           `json:"flowStatus"`
+	StepID     int              `json:"stepId"`
+	TaskID     string           `json:"taskId"`
+	TaskName   string           `json:"taskName"`
+	SubflowID  int              `json:"subflowId,omitempty"`
+	Status     model.TaskStatus `json:"status"`
+	StartTime  time.Time        `json:"startTime"`
+	EndTime    time.Time        `json:"endTime"`
+	DurationMs int64            `json:"durationMs"`
+}
+
+// traceFileWriter writes the execution trace of finished instances to a JSONL file, one line per
+// step.  The steps of an instance are written at once, so the lines of concurrent instances are
+// never interleaved.  When the file would exceed maxBytes it is rotated to <path>.1, replacing the
+// previous rotated file.
+type traceFileWriter struct {
+	mu       sync.Mutex
+	path     string
+	maxBytes int64
+	file     *os.File
+	size     int64
+}
+
+// traceFile is the trace file writer, nil unless the traceFile runtime setting is set
+var traceFile *traceFileWriter
+
+func newTraceFileWriter(path string, maxBytes int64) (*traceFileWriter, error) {
+	w := &traceFileWriter{path: path, maxBytes: maxBytes}
+	if err := w.open(); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+func (w *traceFileWriter) open() error {
+	file, err := os.OpenFile(w.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+
+	w.file = file
+	w.size = info.Size()
+	return nil
+}
+
+// writeInstance writes the steps executed by the instance
+func (w *traceFileWriter) writeInstance(inst *instance.IndependentInstance, status string) error {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	for _, r := range inst.ExecutionTrace() {
+		err := enc.Encode(&traceFileEvent{
+			InstanceID: inst.ID(),
+			FlowName:   inst.Name(),
+			FlowStatus: status,
+			StepID:     r.StepID,
+			TaskID:     r.TaskID,
+			TaskName:   r.TaskName,
+			SubflowID:  r.SubflowID,
+			Status:     r.Status,
+			StartTime:  r.StartTime,
+			EndTime:    r.EndTime,
+			DurationMs: int64(r.Duration() / time.Millisecond),
+		})
+		if err != nil {
+			return err
+		}
+	}
+	if buf.Len() == 0 {
+		return nil
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.maxBytes > 0 && w.size > 0 && w.size+int64(buf.Len()) > w.maxBytes {
+		if err := w.rotate(); err != nil {
+			return err
+		}
+	}
+
+	n, err := w.file.Write(buf.Bytes())
+	w.size += int64(n)
+	return err
+}
+
+func (w *traceFileWriter) rotate() error {
+	if err := w.file.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(w.path, w.path+".1"); err != nil {
+		return err
+	}
+	return w.open()
+}
+
+func (w *traceFileWriter) close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.file.Close()
+}
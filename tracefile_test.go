@@ -0,0 +1,60 @@
+package flow
+
+import (
+	"bufio"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTraceFileWriter(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tracefile")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "trace.jsonl")
+	w, err := newTraceFileWriter(path, 0)
+	assert.Nil(t, err)
+
+	stepper := newSelfTestStepper(t, "selftest-ok")
+	assert.Nil(t, runSelfTest(stepper))
+	assert.Nil(t, w.writeInstance(stepper.Instance(), FlowStatusCompleted))
+	assert.Nil(t, w.close())
+
+	file, err := os.Open(path)
+	assert.Nil(t, err)
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	assert.True(t, scanner.Scan())
+	event := &traceFileEvent{}
+	assert.Nil(t, json.Unmarshal(scanner.Bytes(), event))
+	assert.Equal(t, "selftest-selftest-ok", event.InstanceID)
+	assert.Equal(t, "check", event.TaskID)
+	assert.Equal(t, FlowStatusCompleted, event.FlowStatus)
+	assert.False(t, scanner.Scan())
+}
+
+func TestTraceFileWriterRotate(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tracefile")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "trace.jsonl")
+	w, err := newTraceFileWriter(path, 10)
+	assert.Nil(t, err)
+	defer w.close()
+
+	stepper := newSelfTestStepper(t, "selftest-ok")
+	assert.Nil(t, runSelfTest(stepper))
+	assert.Nil(t, w.writeInstance(stepper.Instance(), FlowStatusCompleted))
+	// the file exceeds the maximum, it's rotated before the next write
+	assert.Nil(t, w.writeInstance(stepper.Instance(), FlowStatusCompleted))
+
+	_, err = os.Stat(path + ".1")
+	assert.Nil(t, err)
+}
@@ -0,0 +1,123 @@
+package flow
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"time"
+
+	"github.com/project-flogo/core/data/coerce"
+	"github.com/project-flogo/core/support/trace"
+	"github.com/project-flogo/flow/instance"
+	flowsupport "github.com/project-flogo/flow/support"
+)
+
+// Span tags set when the trace of a flow instance is finished
+const (
+	// TagSteps is the number of steps executed by the instance
+	TagSteps = "flow.steps"
+	// TagExecutionTime is the execution time of the instance in milliseconds
+	TagExecutionTime = "flow.execution_time_ms"
+	// TagStatus is the final status of the instance, see FlowStatusKey
+	TagStatus = "flow.status"
+	// TagErrorClass classifies the error of a failed instance, it is the error's code if it has
+	// one, otherwise its type
+	TagErrorClass = "flow.error_class"
+	// TagInputSize is the approximate size of the instance's inputs in bytes
+	TagInputSize = "flow.input_size"
+	// TagOutputSize is the approximate size of the instance's outputs in bytes
+	TagOutputSize = "flow.output_size"
+)
+
+// TracingContextExtractor extracts the tracing context of the trigger event from the context the
+// flow is run with, it is used as the parent of the instance's span
+type TracingContextExtractor func(ctx context.Context) trace.TracingContext
+
+var tracingContextExtractor TracingContextExtractor = trace.ExtractTracingContext
+
+// SetTracingContextExtractor sets the TracingContextExtractor used to link the traces of flow
+// instances to their trigger's trace, nil restores the default trace.ExtractTracingContext
+func SetTracingContextExtractor(extractor TracingContextExtractor) {
+	if extractor == nil {
+		extractor = trace.ExtractTracingContext
+	}
+	tracingContextExtractor = extractor
+}
+
+// TracingSampler decides whether an instance of the flow, started with the specified inputs, is
+// traced when tracing is enabled.  Instances started by a traced flow are always traced.
+type TracingSampler func(flowURI string, inputs map[string]interface{}) bool
+
+var tracingSampler TracingSampler
+
+// SetTracingSampler sets the TracingSampler consulted before an instance's trace is started, nil
+// traces all instances
+func SetTracingSampler(sampler TracingSampler) {
+	tracingSampler = sampler
+}
+
+// TraceInputKey is the input that requests the trace of an instance, see RateTracingSampler
+const TraceInputKey = "_trace"
+
+// RateTracingSampler creates a TracingSampler that traces the fraction of instances specified by
+// rate (ex. 0.01 traces 1% of instances), as well as the instances whose TraceInputKey input is true
+func RateTracingSampler(rate float64) TracingSampler {
+	return func(flowURI string, inputs map[string]interface{}) bool {
+		if requested, _ := coerce.ToBool(inputs[TraceInputKey]); requested {
+			return true
+		}
+		return rand.Float64() < rate
+	}
+}
+
+// sampleTrace checks if the instance should be traced
+func sampleTrace(flowURI string, inputs map[string]interface{}, subflowOptions *instance.SubflowOptions) bool {
+	if tracingSampler == nil || (subflowOptions != nil && subflowOptions.ParentTracingContext != nil) {
+		return true
+	}
+	return tracingSampler(flowURI, inputs)
+}
+
+// finishTrace sets the finish tags on the instance's span and finishes its trace
+func finishTrace(inst *instance.IndependentInstance, steps int, status string, inputs, outputs map[string]interface{}, err error) {
+	if traceFile != nil {
+		if writeErr := traceFile.writeInstance(inst, status); writeErr != nil {
+			logger.Warnf("Unable to write trace of Flow Instance [%s] to file: %v", inst.LogID(), writeErr)
+		}
+	}
+
+	tc := inst.TracingContext()
+	if tc == nil {
+		return
+	}
+
+	tags := map[string]interface{}{
+		TagSteps:         steps,
+		TagExecutionTime: int64(inst.ExecutionTime() / time.Millisecond),
+		TagStatus:        status,
+		TagInputSize:     flowsupport.EstimateSize(inputs),
+	}
+	if outputs != nil {
+		tags[TagOutputSize] = flowsupport.EstimateSize(outputs)
+	}
+	if err != nil {
+		tags[TagErrorClass] = errorClass(err)
+	}
+	tc.SetTags(tags)
+
+	_ = trace.GetTracer().FinishTrace(tc, err)
+}
+
+func errorClass(err error) string {
+	switch t := err.(type) {
+	case *instance.ErrorWithCode:
+		if t.Code != "" {
+			return t.Code
+		}
+	case interface{ Code() string }:
+		if code := t.Code(); code != "" {
+			return code
+		}
+	}
+	return fmt.Sprintf("%T", err)
+}
@@ -0,0 +1,22 @@
+package flow
+
+import (
+	"testing"
+
+	"github.com/project-flogo/flow/instance"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSampleTrace(t *testing.T) {
+	defer SetTracingSampler(nil)
+
+	assert.True(t, sampleTrace("res://flow:a", nil, nil))
+
+	SetTracingSampler(RateTracingSampler(0))
+	assert.False(t, sampleTrace("res://flow:a", nil, nil))
+	assert.True(t, sampleTrace("res://flow:a", map[string]interface{}{TraceInputKey: "true"}, nil))
+	assert.False(t, sampleTrace("res://flow:a", nil, &instance.SubflowOptions{}))
+
+	SetTracingSampler(RateTracingSampler(1))
+	assert.True(t, sampleTrace("res://flow:a", nil, nil))
+}
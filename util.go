@@ -0,0 +1,55 @@
+package flow
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/project-flogo/core/data/coerce"
+	"github.com/project-flogo/flow/instance"
+)
+
+// toDuration converts a setting value to a duration, strings are parsed as Go durations
+// (ex. "30s") and numbers are treated as milliseconds
+func toDuration(val interface{}) (time.Duration, error) {
+	if val == nil {
+		return 0, nil
+	}
+
+	if s, ok := val.(string); ok {
+		if s == "" {
+			return 0, nil
+		}
+		if d, err := time.ParseDuration(s); err == nil {
+			return d, nil
+		}
+	}
+
+	ms, err := coerce.ToInt64(val)
+	if err != nil {
+		return 0, fmt.Errorf("invalid duration '%v'", val)
+	}
+
+	return time.Duration(ms) * time.Millisecond, nil
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// resolveTimeout returns the maximum duration of an instance, which is the first that is set of:
+// the ExecOptions.MaxDuration of the invocation, the flow's DefaultTimeout setting and the
+// engine's defaultFlowTimeout.  usingDefault indicates that the engine's default is used.
+func resolveTimeout(execOptions *instance.ExecOptions, flowTimeout time.Duration) (timeout time.Duration, usingDefault bool) {
+	if execOptions != nil && execOptions.MaxDuration > 0 {
+		return execOptions.MaxDuration, false
+	}
+	if flowTimeout > 0 {
+		return flowTimeout, false
+	}
+	return defaultFlowTimeout, true
+}
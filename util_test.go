@@ -0,0 +1,47 @@
+package flow
+
+import (
+	"testing"
+	"time"
+
+	"github.com/project-flogo/flow/instance"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestToDuration(t *testing.T) {
+	d, err := toDuration(nil)
+	assert.Nil(t, err)
+	assert.Equal(t, time.Duration(0), d)
+
+	d, err = toDuration("30s")
+	assert.Nil(t, err)
+	assert.Equal(t, 30*time.Second, d)
+
+	d, err = toDuration(1500)
+	assert.Nil(t, err)
+	assert.Equal(t, 1500*time.Millisecond, d)
+
+	d, err = toDuration("250")
+	assert.Nil(t, err)
+	assert.Equal(t, 250*time.Millisecond, d)
+
+	_, err = toDuration("soon")
+	assert.NotNil(t, err)
+}
+
+func TestResolveTimeout(t *testing.T) {
+	defaultFlowTimeout = time.Minute
+	defer func() { defaultFlowTimeout = 0 }()
+
+	d, usingDefault := resolveTimeout(nil, 0)
+	assert.Equal(t, time.Minute, d)
+	assert.True(t, usingDefault)
+
+	d, usingDefault = resolveTimeout(&instance.ExecOptions{}, 30*time.Second)
+	assert.Equal(t, 30*time.Second, d)
+	assert.False(t, usingDefault)
+
+	d, usingDefault = resolveTimeout(&instance.ExecOptions{MaxDuration: 2 * time.Minute}, 30*time.Second)
+	assert.Equal(t, 2*time.Minute, d)
+	assert.False(t, usingDefault)
+}